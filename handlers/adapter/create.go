@@ -49,7 +49,7 @@ func CreateHandler(env string, project string, name string, filePath string, res
 			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
 			AccessToken: viper.GetString(keys.DhCoreAccessToken),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	// ctx per il CrudService e per la Create
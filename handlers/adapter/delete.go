@@ -81,7 +81,7 @@ func DeleteHandler(env string, project string, name string, confirm bool, cascad
 			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
 			AccessToken: viper.GetString(keys.DhCoreAccessToken),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	ctx := context.Background()
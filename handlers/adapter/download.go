@@ -13,18 +13,15 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/config"
-
 	"dhcli/handlers/utils"
 	"dhcli/keys"
 
 	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/services/transfer"
 
-	"github.com/spf13/viper"
 	"sigs.k8s.io/yaml"
 )
 
-func DownloadHandler(env string, destination string, output string, project string, name string, resource string, id string, verbose bool) error {
+func DownloadHandler(env string, destination string, output string, project string, name string, resource string, id string, verbose bool, noCache bool, extract bool) error {
 
 	utils.CheckUpdateEnvironment()
 	utils.CheckApiLevel(keys.ApiLevelKey, keys.LoginMin, keys.LoginMax)
@@ -37,36 +34,22 @@ func DownloadHandler(env string, destination string, output string, project stri
 		return errors.New("project is mandatory for non-project resources")
 	}
 
-	// Traduce viper -> sdk.Config (INI/ENV/flags già caricati nel PersistentPreRunE)
-	cfg := config.Config{
-		Core: config.CoreConfig{
-			BaseURL:     viper.GetString(keys.DhCoreEndpoint),
-			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
-			AccessToken: viper.GetString(keys.DhCoreAccessToken),
-		},
-		S3: config.S3Config{
-			AccessKey:   viper.GetString("aws_access_key_id"),
-			SecretKey:   viper.GetString("aws_secret_access_key"),
-			AccessToken: viper.GetString("aws_session_token"),
-			Region:      viper.GetString("aws_region"),
-			EndpointURL: viper.GetString("aws_endpoint_url"),
-		},
-		HTTPClient: utils.GetDebugHTTPClient(),
-	}
-
-	svc, err := transfer.NewTransferService(context.Background(), cfg)
+	// The SDK S3 client only takes static credentials, so the transfer
+	// service gets none: downloadEntity moves S3 objects with a client that
+	// refreshes them and leaves the other schemes to the SDK.
+	svc, err := transfer.NewTransferService(context.Background(), coreConfig())
 	if err != nil {
 		return fmt.Errorf("sdk init failed: %w", err)
 	}
 
-	infos, err := svc.Download(context.Background(), endpoint, transfer.DownloadRequest{
+	infos, err := downloadEntity(context.Background(), svc, endpoint, transfer.DownloadRequest{
 		Project:     project,
 		Resource:    resource,
 		ID:          id,
 		Name:        name,
 		Destination: destination,
 		Verbose:     verbose,
-	})
+	}, downloadOptions{NoCache: noCache, Extract: extract})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/services/transfer"
+
+	"dhcli/handlers/cache"
+	"dhcli/handlers/utils"
+)
+
+// partSuffix marks an incomplete HTTP download that can be resumed.
+const partSuffix = ".part"
+
+// transportURL returns the path without its archive prefix, keeping the
+// query string that ParsePath drops.
+func transportURL(pathStr string, pp *utils.ParsedPath) string {
+	if pp.Archive == "" {
+		return pathStr
+	}
+	if i := strings.Index(pathStr, "+"); i >= 0 {
+		return pathStr[i+1:]
+	}
+	return pathStr
+}
+
+// downloadHTTP fetches a single HTTP(S) resource. Data is written to a .part
+// file next to the target; when one is left over from an interrupted run,
+// only the missing bytes are requested. Servers ignoring the Range header
+// make the download restart from scratch.
+func downloadHTTP(ctx context.Context, url string, pp *utils.ParsedPath, destination string) ([]transfer.DownloadInfo, error) {
+	logger := utils.GetGlobalLogger()
+
+	if strings.HasSuffix(pp.Path, "/") || pp.Filename == "" || pp.Filename == "/" || pp.Filename == "." {
+		return nil, fmt.Errorf("cannot download a directory over HTTP: %s", url)
+	}
+
+	target, err := chooseLocalTarget(destination, pp.Filename)
+	if err != nil {
+		return nil, err
+	}
+	part := target + partSuffix
+
+	var offset int64
+	if st, err := os.Stat(part); err == nil {
+		offset = st.Size()
+	}
+
+	client := utils.NewHTTPClient(0)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	total := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			return nil, fmt.Errorf("unexpected Content-Range %q from %s", resp.Header.Get("Content-Range"), url)
+		}
+		logger.Info(fmt.Sprintf("Resuming %s at %s", url, cache.HumanSize(offset)))
+		flags |= os.O_APPEND
+		total = size
+	case http.StatusOK:
+		if offset > 0 {
+			logger.Warn(fmt.Sprintf("%s does not support ranged requests, restarting download", url))
+		}
+		flags |= os.O_TRUNC
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The leftover part may already hold the whole resource.
+		_, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || size != offset {
+			return nil, fmt.Errorf("cannot resume %s: %s", url, resp.Status)
+		}
+		return finishHTTPDownload(part, target, offset)
+	default:
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	f, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Downloading %s → %s", url, target))
+	progress := newTransferProgress(total)
+	progress.done = offset
+	n, err := io.Copy(io.MultiWriter(f, progress), resp.Body)
+	progress.Finish()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("download interrupted after %s, run the command again to resume: %w", cache.HumanSize(offset+n), err)
+	}
+	if total > 0 && offset+n != total {
+		return nil, fmt.Errorf("download incomplete (%s of %s), run the command again to resume", cache.HumanSize(offset+n), cache.HumanSize(total))
+	}
+	return finishHTTPDownload(part, target, offset+n)
+}
+
+func finishHTTPDownload(part string, target string, size int64) ([]transfer.DownloadInfo, error) {
+	if err := os.Rename(part, target); err != nil {
+		return nil, err
+	}
+	return []transfer.DownloadInfo{{Filename: filepath.Base(target), Size: size, Path: target}}, nil
+}
+
+// parseContentRange parses "bytes start-end/size" and "bytes */size". The
+// size is -1 when unknown.
+func parseContentRange(v string) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(v, "bytes ")
+	if !ok {
+		return 0, 0, errors.New("invalid Content-Range")
+	}
+	rng, sizeStr, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, errors.New("invalid Content-Range")
+	}
+	size := int64(-1)
+	if sizeStr != "*" {
+		n, err := strconv.ParseInt(sizeStr, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		size = n
+	}
+	if rng == "*" {
+		return 0, size, nil
+	}
+	startStr, _, _ := strings.Cut(rng, "-")
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, size, nil
+}
+
+// downloadLocal copies a file or a directory tree from a file:// path,
+// following the same destination rules as S3 downloads.
+func downloadLocal(pp *utils.ParsedPath, destination string) ([]transfer.DownloadInfo, error) {
+	src := filepath.FromSlash(pp.Path)
+	st, err := os.Stat(src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access %s: %w", src, err)
+	}
+
+	if !st.IsDir() {
+		target, err := chooseLocalTarget(destination, st.Name())
+		if err != nil {
+			return nil, err
+		}
+		if err := copyFile(src, target); err != nil {
+			return nil, err
+		}
+		return []transfer.DownloadInfo{{Filename: filepath.Base(target), Size: st.Size(), Path: target}}, nil
+	}
+
+	locals, _, err := listLocalFiles(src, filepath.ToSlash(destination))
+	if err != nil {
+		return nil, err
+	}
+	var out []transfer.DownloadInfo
+	for _, lf := range locals {
+		target := filepath.FromSlash(lf.dest)
+		if err := copyFile(lf.path, target); err != nil {
+			return out, err
+		}
+		out = append(out, transfer.DownloadInfo{Filename: filepath.Base(target), Size: lf.info.Size(), Path: target})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no files found at %s", src)
+	}
+	return out, nil
+}
+
+// copyFile copies src to target, creating parent directories.
+func copyFile(src string, target string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if srcInfo, err := in.Stat(); err == nil {
+		if dstInfo, err := os.Stat(target); err == nil && os.SameFile(srcInfo, dstInfo) {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
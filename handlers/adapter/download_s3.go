@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/services/transfer"
+	"github.com/spf13/viper"
+
+	"dhcli/handlers/cache"
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// downloadOptions tunes downloadEntity.
+type downloadOptions struct {
+	// NoCache bypasses the local download cache.
+	NoCache bool
+	// Extract unpacks archives after transfer.
+	Extract bool
+}
+
+// downloadEntity resolves the entity and fetches its path according to the
+// scheme: S3 objects go through the local cache, HTTP(S) resources are fetched
+// with resumable ranged requests and file:// paths are copied. Other schemes
+// are delegated to the SDK transfer service.
+func downloadEntity(ctx context.Context, svc *transfer.TransferService, endpoint string, req transfer.DownloadRequest, opts downloadOptions) ([]transfer.DownloadInfo, error) {
+	if req.ID == "" && req.Name == "" {
+		return nil, errors.New("you must specify id or name")
+	}
+
+	entity, err := fetchEntity(ctx, req.Project, endpoint, req.ID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	pathStr := entitySpecPath(entity)
+	pp, err := utils.ParsePath(pathStr)
+	if err != nil {
+		// left to the SDK, as any scheme not handled here
+		pp = &utils.ParsedPath{}
+	}
+
+	var out []transfer.DownloadInfo
+	switch pp.Scheme {
+	case "s3":
+		out, err = downloadS3(ctx, entity, endpoint, pathStr, pp, req.Destination, opts)
+	case "http", "https":
+		out, err = downloadHTTP(ctx, transportURL(pathStr, pp), pp, req.Destination)
+	case "file":
+		out, err = downloadLocal(pp, req.Destination)
+	default:
+		out, err = svc.Download(ctx, endpoint, req)
+	}
+	if err != nil || !opts.Extract {
+		return out, err
+	}
+	return extractDownloads(out, pp)
+}
+
+// downloadS3 serves every object from the local cache when its ETag is
+// unchanged. Objects missing from the cache are fetched once, stored, and
+// then materialized at the destination.
+func downloadS3(ctx context.Context, entity map[string]interface{}, endpoint string, pathStr string, pp *utils.ParsedPath, destination string, opts downloadOptions) ([]transfer.DownloadInfo, error) {
+	logger := utils.GetGlobalLogger()
+
+	var err error
+	var store *cache.Store
+	if !opts.NoCache {
+		if store, err = cache.Open(); err != nil {
+			return nil, err
+		}
+	}
+	client, err := utils.NewS3Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entityKey := utils.GetStringValue(entity, "key")
+	if entityKey == "" {
+		entityKey = fmt.Sprintf("%s/%v", endpoint, entity["id"])
+	}
+
+	prefix := pp.Path
+	isDir := strings.HasSuffix(prefix, "/")
+
+	objects, err := utils.ListS3Objects(ctx, client, pp.Host, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !isDir {
+		var exact []utils.S3Object
+		for _, o := range objects {
+			if o.Key == prefix {
+				exact = append(exact, o)
+			}
+		}
+		objects = exact
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no objects found at %s", pathStr)
+	}
+
+	singleTarget := ""
+	if !isDir {
+		if singleTarget, err = chooseLocalTarget(destination, pp.Filename); err != nil {
+			return nil, err
+		}
+	}
+
+	var out []transfer.DownloadInfo
+	for _, obj := range objects {
+		target := singleTarget
+		if isDir {
+			if target, err = utils.SafeJoin(destination, strings.TrimPrefix(obj.Key, prefix)); err != nil {
+				return out, fmt.Errorf("object %s: %w", obj.Key, err)
+			}
+		}
+		object := fmt.Sprintf("s3://%s/%s", pp.Host, obj.Key)
+
+		if store == nil || obj.ETag == "" {
+			// No cache, or no version information to key it on.
+			if store != nil {
+				logger.Info(fmt.Sprintf("No ETag for %s, downloading without cache", object))
+			}
+			if err := downloadToFile(ctx, client, pp.Host, obj.Key, target); err != nil {
+				return out, err
+			}
+		} else {
+			blob, hit := store.Lookup(entityKey, object, obj.ETag)
+			if hit {
+				logger.Info(fmt.Sprintf("Cache hit: %s", object))
+			} else {
+				logger.Info(fmt.Sprintf("Cache miss: %s", object))
+				blob, err = store.Put(entityKey, object, obj.ETag, func(w io.Writer) error {
+					return utils.DownloadS3Object(ctx, client, pp.Host, obj.Key, w)
+				})
+				if err != nil {
+					return out, err
+				}
+			}
+			if err := cache.Materialize(blob, target); err != nil {
+				return out, fmt.Errorf("failed to write %s: %w", target, err)
+			}
+		}
+
+		out = append(out, transfer.DownloadInfo{
+			Filename: filepath.Base(target),
+			Size:     obj.Size,
+			Path:     target,
+		})
+	}
+
+	// Optional automatic eviction after each download.
+	if store == nil {
+		return out, nil
+	}
+	if limit := viper.GetString(keys.CacheMaxSize); limit != "" {
+		if n, err := cache.ParseSize(limit); err != nil {
+			logger.Warn(fmt.Sprintf("Ignoring %s: %v", keys.CacheMaxSize, err))
+		} else if _, err := store.Prune(n, 0); err != nil {
+			logger.Warn(fmt.Sprintf("Cache eviction failed: %v", err))
+		}
+	}
+
+	return out, nil
+}
+
+// extractDownloads unpacks downloaded archives next to themselves, removes
+// the archives and returns the extracted files instead.
+func extractDownloads(infos []transfer.DownloadInfo, pp *utils.ParsedPath) ([]transfer.DownloadInfo, error) {
+	logger := utils.GetGlobalLogger()
+
+	var out []transfer.DownloadInfo
+	for _, info := range infos {
+		format := pp.Archive
+		if format == "" {
+			format = utils.ArchiveFormatFromName(info.Path)
+		}
+		if format == "" {
+			logger.Warn(fmt.Sprintf("%s is not an archive, leaving it as is", info.Path))
+			out = append(out, info)
+			continue
+		}
+
+		logger.Info(fmt.Sprintf("Extracting %s ...", info.Path))
+		files, err := utils.ExtractArchive(info.Path, format, filepath.Dir(info.Path))
+		if err != nil {
+			return out, fmt.Errorf("failed to extract %s: %w", info.Path, err)
+		}
+		if err := os.Remove(info.Path); err != nil {
+			logger.Warn(fmt.Sprintf("Cannot remove %s: %v", info.Path, err))
+		}
+		for _, f := range files {
+			st, err := os.Stat(f)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, transfer.DownloadInfo{Filename: filepath.Base(f), Size: st.Size(), Path: f})
+		}
+	}
+	return out, nil
+}
+
+// downloadToFile writes a single object straight to target.
+func downloadToFile(ctx context.Context, client *s3.Client, bucket, key, target string) error {
+	if dir := filepath.Dir(target); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return utils.DownloadS3Object(ctx, client, bucket, key, f)
+}
+
+// chooseLocalTarget mirrors the SDK rules for single files:
+// - empty destination          → filename in the cwd
+// - existing directory         → dst/filename
+// - existing file              → dst
+// - missing destination        → create directory dst and use dst/filename
+func chooseLocalTarget(dst, filename string) (string, error) {
+	if dst == "" {
+		return filename, nil
+	}
+	info, err := os.Stat(dst)
+	if err == nil {
+		if info.IsDir() {
+			return filepath.Join(dst, filename), nil
+		}
+		return dst, nil
+	}
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(dst, 0o755); err != nil {
+			return "", err
+		}
+		return filepath.Join(dst, filename), nil
+	}
+	return "", err
+}
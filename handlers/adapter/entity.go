@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/config"
+	crudsvc "github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/services/crud"
+	"github.com/spf13/viper"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// coreConfig bridges the Viper settings of the current environment to the SDK
+// configuration.
+func coreConfig() config.Config {
+	return config.Config{
+		Core: config.CoreConfig{
+			BaseURL:     viper.GetString(keys.DhCoreEndpoint),
+			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
+			AccessToken: viper.GetString(keys.DhCoreAccessToken),
+		},
+		HTTPClient: utils.NewHTTPClient(0),
+	}
+}
+
+// fetchEntity retrieves a single entity by id, or the latest version by name,
+// and returns it as a generic map.
+func fetchEntity(ctx context.Context, project string, endpoint string, id string, name string) (map[string]interface{}, error) {
+	crud, err := crudsvc.NewCrudService(ctx, coreConfig())
+	if err != nil {
+		return nil, fmt.Errorf("sdk init failed: %w", err)
+	}
+
+	body, _, err := crud.Get(ctx, crudsvc.GetRequest{
+		ResourceRequest: crudsvc.ResourceRequest{
+			Project:  project,
+			Resource: endpoint,
+		},
+		ID:   id,
+		Name: name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error in request: %w", err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return utils.GetFirstIfList(m)
+}
+
+// entitySpecPath returns spec.path of an entity, or an empty string.
+func entitySpecPath(entity map[string]interface{}) string {
+	if spec, ok := entity["spec"].(map[string]interface{}); ok {
+		if p, ok := spec["path"].(string); ok {
+			return p
+		}
+	}
+	return ""
+}
@@ -5,6 +5,7 @@
 package adapter
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -85,6 +87,11 @@ func (s stompLogger) Info(m string)    { s.l.Debug("[STOMP] " + m) }
 func (s stompLogger) Warning(m string) { s.l.Debug("[STOMP] WARN " + m) }
 func (s stompLogger) Error(m string)   { s.l.Debug("[STOMP] ERR " + m) }
 
+const (
+	eventsMinBackoff = time.Second
+	eventsMaxBackoff = 30 * time.Second
+)
+
 // EventsHandler connects to the STOMP broker via WebSocket and streams
 // push-notifications for the given resource (and optionally a specific ID).
 func EventsHandler(env string, output string, project string, name string, resource string, id string) error {
@@ -98,7 +105,6 @@ func EventsHandler(env string, output string, project string, name string, resou
 	format := utils.TranslateFormat(output)
 
 	baseURL := viper.GetString(keys.DhCoreEndpoint)
-	accessToken := viper.GetString(keys.DhCoreAccessToken)
 
 	wsURL, err := buildWSURL(baseURL)
 	if err != nil {
@@ -111,11 +117,130 @@ func EventsHandler(env string, output string, project string, name string, resou
 		destination += "/" + id
 	}
 
+	tokens := utils.CurrentTokenSource()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tokens.KeepFresh(ctx)
+
+	// Closing the raw WebSocket unblocks the STOMP reader goroutine, which
+	// closes sub.C and lets the range loop below exit cleanly.
+	var (
+		connMu  sync.Mutex
+		current *websocket.Conn
+	)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		signal.Stop(sigCh)
+		cancel()
+		connMu.Lock()
+		if current != nil {
+			current.Close()
+		}
+		connMu.Unlock()
+	}()
+
+	// The connection is opened again when the broker drops it, e.g. once the
+	// token it was opened with expires, with a token asked for each time.
+	connected := false
+	backoff := eventsMinBackoff
+	// wait sleeps for the backoff, which grows for the next attempt, and
+	// reports false when interrupted.
+	wait := func() bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, eventsMaxBackoff)
+		return true
+	}
+	for {
+		accessToken, err := tokens.Token()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		wsConn, stompConn, sub, err := subscribeEvents(wsURL, destination, accessToken)
+		if err != nil {
+			if !connected {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "warning: %v, retrying in %s\n", err, backoff)
+			if !wait() {
+				return nil
+			}
+			continue
+		}
+
+		connMu.Lock()
+		current = wsConn
+		connMu.Unlock()
+		if ctx.Err() != nil {
+			// interrupted while connecting
+			wsConn.Close()
+		}
+		if connected {
+			fmt.Fprintf(os.Stderr, "Reconnected to %s\n", destination)
+		} else {
+			fmt.Fprintf(os.Stderr, "Subscribed to %s — waiting for events (Ctrl+C to stop)\n", destination)
+		}
+		connected = true
+
+		for msg := range sub.C {
+			if msg.Err != nil {
+				// the connection was closed, by a signal or by the broker
+				break
+			}
+			// the session works: the next loss starts over from the
+			// shortest delay
+			backoff = eventsMinBackoff
+
+			var event map[string]interface{}
+			if err := json.Unmarshal(msg.Body, &event); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not parse message: %v\n", err)
+				continue
+			}
+
+			// Client-side filters.
+			if project != "" && !eventMatchesProject(event, project) {
+				continue
+			}
+			if name != "" && !eventMatchesName(event, name) {
+				continue
+			}
+
+			if err := printEvent(event, format); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not render event: %v\n", err)
+			}
+		}
+		stompConn.MustDisconnect()
+		wsConn.Close()
+
+		if ctx.Err() != nil {
+			// Normal on shutdown; suppress the noise.
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "warning: connection to %s lost, reconnecting in %s\n", destination, backoff)
+		if !wait() {
+			return nil
+		}
+	}
+}
+
+// subscribeEvents opens the WebSocket, connects to the STOMP broker with the
+// token and subscribes to destination.
+func subscribeEvents(wsURL string, destination string, accessToken string) (*websocket.Conn, *stomp.Conn, *stomp.Subscription, error) {
 	// Dial WebSocket.
-	dialer := websocket.DefaultDialer
+	transport := utils.HTTPTransport()
+	dialer := &websocket.Dialer{
+		Proxy:            transport.Proxy,
+		TLSClientConfig:  transport.TLSClientConfig,
+		HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+	}
 	wsConn, _, err := dialer.Dial(wsURL, http.Header{})
 	if err != nil {
-		return fmt.Errorf("WebSocket dial failed: %w", err)
+		return nil, nil, nil, fmt.Errorf("WebSocket dial failed: %w", err)
 	}
 	netConn := &wsNetConn{conn: wsConn, debug: utils.GetDebugHTTPClient() != nil}
 
@@ -131,54 +256,17 @@ func EventsHandler(env string, output string, project string, name string, resou
 	stompConn, err := stomp.Connect(netConn, stompOpts...)
 	if err != nil {
 		wsConn.Close()
-		return fmt.Errorf("STOMP connect failed: %w", err)
+		return nil, nil, nil, fmt.Errorf("STOMP connect failed: %w", err)
 	}
-	defer stompConn.Disconnect()
 
 	// Subscribe.
 	sub, err := stompConn.Subscribe(destination, stomp.AckAuto)
 	if err != nil {
-		return fmt.Errorf("STOMP subscribe to %q failed: %w", destination, err)
-	}
-	defer sub.Unsubscribe()
-
-	fmt.Fprintf(os.Stderr, "Subscribed to %s — waiting for events (Ctrl+C to stop)\n", destination)
-
-	// Closing the raw WebSocket unblocks the STOMP reader goroutine, which
-	// closes sub.C and lets the range loop below exit cleanly.
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		signal.Stop(sigCh)
+		stompConn.MustDisconnect()
 		wsConn.Close()
-	}()
-
-	for msg := range sub.C {
-		if msg.Err != nil {
-			// Normal on shutdown; suppress the noise.
-			return nil
-		}
-
-		var event map[string]interface{}
-		if err := json.Unmarshal(msg.Body, &event); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: could not parse message: %v\n", err)
-			continue
-		}
-
-		// Client-side filters.
-		if project != "" && !eventMatchesProject(event, project) {
-			continue
-		}
-		if name != "" && !eventMatchesName(event, name) {
-			continue
-		}
-
-		if err := printEvent(event, format); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: could not render event: %v\n", err)
-		}
+		return nil, nil, nil, fmt.Errorf("STOMP subscribe to %q failed: %w", destination, err)
 	}
-	return nil
+	return wsConn, stompConn, sub, nil
 }
 
 // buildWSURL converts an http(s) core endpoint into a ws(s)://host/ws URL.
@@ -45,7 +45,7 @@ func GetHandler(env string, output string, project string, name string, resource
 			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
 			AccessToken: viper.GetString(keys.DhCoreAccessToken),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	ctx := context.Background()
@@ -47,7 +47,7 @@ func ListResourcesHandler(env string, output string, project string, name string
 			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
 			AccessToken: viper.GetString(keys.DhCoreAccessToken),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	ctx := context.Background()
@@ -40,20 +40,14 @@ func LogHandler(env string, project string, container string, follow bool, id st
 	// Bridge viper -> sdk config
 	cfg := config.Config{
 		Core: config.CoreConfig{
-			BaseURL:     viper.GetString(keys.DhCoreEndpoint),
-			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
-			AccessToken: viper.GetString(keys.DhCoreAccessToken),
+			BaseURL:    viper.GetString(keys.DhCoreEndpoint),
+			APIVersion: viper.GetString(keys.DhCoreApiVersion),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	ctx := context.Background()
-
-	// Nuovo RunService (globale) al posto di LogService
-	svc, err := runsvc.NewRunService(ctx, cfg)
-	if err != nil {
-		return err
-	}
+	tokens := utils.CurrentTokenSource()
 
 	// Track the last printed tail of the log to handle circular buffers
 	// We'll search for this tail in new logs to find where we left off
@@ -62,6 +56,19 @@ func LogHandler(env string, project string, container string, follow bool, id st
 
 	// Loop requests if following
 	for {
+		// The token may be renewed between two polls
+		token, err := tokens.Token()
+		if err != nil {
+			utils.GetGlobalLogger().Warn(err.Error())
+		}
+		cfg.Core.AccessToken = token
+
+		// Nuovo RunService (globale) al posto di LogService
+		svc, err := runsvc.NewRunService(ctx, cfg)
+		if err != nil {
+			return err
+		}
+
 		containerLog, err := getContainerLogAdapter(ctx, svc, project, endpoint, id, container)
 		if err != nil {
 			return err
@@ -38,7 +38,7 @@ func MetricsHandler(env string, output string, project string, scope string, id
 
 	baseURL := strings.TrimRight(viper.GetString(keys.DhCoreEndpoint), "/")
 	apiVersion := viper.GetString(keys.DhCoreApiVersion)
-	accessToken := viper.GetString(keys.DhCoreAccessToken)
+	tokens := utils.CurrentTokenSource()
 
 	var metricsURL string
 	switch scope {
@@ -61,13 +61,15 @@ func MetricsHandler(env string, output string, project string, scope string, id
 		return fmt.Errorf("unknown scope: %s", scope)
 	}
 
-	httpClient := utils.GetDebugHTTPClient()
-	if httpClient == nil {
-		httpClient = &http.Client{}
-	}
+	httpClient := utils.NewHTTPClient(0)
 
 	first := true
 	for {
+		// The token may be renewed between two polls
+		accessToken, err := tokens.Token()
+		if err != nil {
+			utils.GetGlobalLogger().Warn(err.Error())
+		}
 		body, err := fetchMetrics(httpClient, metricsURL, accessToken)
 		if err != nil {
 			return err
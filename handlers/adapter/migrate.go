@@ -0,0 +1,378 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	crudsvc "github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/services/crud"
+	"sigs.k8s.io/yaml"
+
+	"dhcli/handlers/cache"
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// migrateEndpoints lists the resources whose data lives at spec.path.
+var migrateEndpoints = []string{"artifacts", "dataitems", "models"}
+
+// Migration outcomes.
+const (
+	MigratePlanned  = "planned"
+	MigrateMigrated = "migrated"
+	MigrateSkipped  = "skipped"
+	MigrateFailed   = "failed"
+)
+
+// MigrateResult reports the migration of a single entity.
+type MigrateResult struct {
+	Resource string `json:"resource"        yaml:"resource"`
+	Name     string `json:"name"            yaml:"name"`
+	ID       string `json:"id"              yaml:"id"`
+	From     string `json:"from"            yaml:"from"`
+	To       string `json:"to,omitempty"    yaml:"to,omitempty"`
+	Objects  int    `json:"objects"         yaml:"objects"`
+	Size     int64  `json:"size"            yaml:"size"`
+	Status   string `json:"status"          yaml:"status"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// MigrateOptions tunes StorageMigrateHandler.
+type MigrateOptions struct {
+	// From restricts the migration to paths under this s3:// location; the
+	// matched prefix is replaced by To.
+	From string
+	To   string
+	// ToEndpoint targets another S3-compatible store, using the standard AWS
+	// credential chain instead of the environment credentials.
+	ToEndpoint string
+	ToRegion   string
+	DryRun     bool
+}
+
+// migration holds the state shared by the per-entity steps.
+type migration struct {
+	src, dst   *s3.Client
+	sameStore  bool
+	fromBucket string
+	fromPrefix string
+	toBucket   string
+	toPrefix   string
+	dryRun     bool
+}
+
+// StorageMigrateHandler copies the data of every artifact, dataitem and model
+// of a project to a new S3 location and points spec.path to it. Objects are
+// copied server-side when both locations share the store, streamed otherwise,
+// and their sizes are checked before the entity is updated. Entities already
+// at the destination and objects already copied are skipped, so an
+// interrupted migration can simply be run again.
+func StorageMigrateHandler(env string, output string, project string, opts MigrateOptions) error {
+	logger := utils.GetGlobalLogger()
+
+	utils.CheckUpdateEnvironment()
+	utils.CheckApiLevel(keys.ApiLevelKey, keys.UpdateMin, keys.UpdateMax)
+	if err := utils.CheckCredentials(); err != nil {
+		return err
+	}
+
+	if project == "" {
+		return errors.New("project is mandatory")
+	}
+
+	to, err := utils.ParsePath(opts.To)
+	if err != nil || to.Scheme != "s3" || to.Host == "" || to.Archive != "" {
+		return fmt.Errorf("destination must be an s3://bucket[/prefix] location, got %q", opts.To)
+	}
+	m := &migration{
+		toBucket:  to.Host,
+		toPrefix:  strings.Trim(to.Path, "/"),
+		sameStore: opts.ToEndpoint == "",
+		dryRun:    opts.DryRun,
+	}
+	if opts.From != "" {
+		from, err := utils.ParsePath(opts.From)
+		if err != nil || from.Scheme != "s3" || from.Host == "" || from.Archive != "" {
+			return fmt.Errorf("source must be an s3://bucket[/prefix] location, got %q", opts.From)
+		}
+		m.fromBucket = from.Host
+		m.fromPrefix = strings.Trim(from.Path, "/")
+	}
+	if m.sameStore && m.fromBucket == m.toBucket && m.fromPrefix == m.toPrefix {
+		return errors.New("source and destination are the same location")
+	}
+
+	ctx := context.Background()
+	if err := utils.EnsureS3Credentials(); err != nil {
+		return err
+	}
+	if m.src, err = utils.NewS3Client(ctx); err != nil {
+		return err
+	}
+	m.dst = m.src
+	if !m.sameStore {
+		if m.dst, err = utils.NewS3ClientForEndpoint(ctx, opts.ToEndpoint, opts.ToRegion); err != nil {
+			return err
+		}
+	}
+
+	crud, err := crudsvc.NewCrudService(ctx, coreConfig())
+	if err != nil {
+		return fmt.Errorf("sdk init failed: %w", err)
+	}
+
+	var results []MigrateResult
+	failed := 0
+	for _, endpoint := range migrateEndpoints {
+		elements, _, err := crud.ListAllPages(ctx, crudsvc.ListRequest{
+			ResourceRequest: crudsvc.ResourceRequest{Project: project, Resource: endpoint},
+			Params:          map[string]string{"versions": "all", "size": "200"},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", endpoint, err)
+		}
+
+		for _, el := range elements {
+			entity, ok := el.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			res := m.migrateEntity(ctx, crud, project, endpoint, entity)
+			if res == nil {
+				continue
+			}
+			switch res.Status {
+			case MigrateFailed:
+				failed++
+				logger.Error(fmt.Sprintf("%s %s: %s", endpoint, res.ID, res.Error))
+			case MigrateMigrated:
+				logger.Success(fmt.Sprintf("%s %s → %s", endpoint, res.ID, res.To))
+			}
+			results = append(results, *res)
+		}
+	}
+
+	switch utils.TranslateFormat(output) {
+	case "json":
+		b, err := json.MarshalIndent(results, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		printMigrateShort(results)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d entities could not be migrated; run the command again to resume", failed)
+	}
+	return nil
+}
+
+// migrateEntity moves the data of one entity. It returns nil for entities
+// outside the migration scope (no S3 path, or not under --from).
+func (m *migration) migrateEntity(ctx context.Context, crud *crudsvc.CrudService, project string, endpoint string, entity map[string]interface{}) *MigrateResult {
+	pathStr := entitySpecPath(entity)
+	pp, err := utils.ParsePath(pathStr)
+	if err != nil || pp.Scheme != "s3" {
+		return nil
+	}
+
+	res := &MigrateResult{
+		Resource: endpoint,
+		Name:     utils.GetStringValue(entity, "name"),
+		ID:       utils.GetStringValue(entity, "id"),
+		From:     pathStr,
+	}
+
+	if pp.Host == m.toBucket && underPrefix(pp.Path, m.toPrefix) {
+		// on another store the same bucket and prefix may also exist at the
+		// source, so the objects must be found at the destination
+		if m.sameStore {
+			res.Status = MigrateSkipped
+			return res
+		}
+		objects, err := listEntityObjects(ctx, m.dst, pp.Host, pp.Path)
+		if err != nil {
+			res.Status = MigrateFailed
+			res.Error = fmt.Sprintf("cannot check the destination: %v", err)
+			return res
+		}
+		if len(objects) > 0 {
+			res.Status = MigrateSkipped
+			return res
+		}
+	}
+	if m.fromBucket != "" && (pp.Host != m.fromBucket || !underPrefix(pp.Path, m.fromPrefix)) {
+		return nil
+	}
+
+	rel := pp.Path
+	if m.fromPrefix != "" {
+		rel = strings.TrimPrefix(strings.TrimPrefix(rel, m.fromPrefix), "/")
+	}
+	newKey := joinKey(m.toPrefix, rel)
+	if strings.HasSuffix(pp.Path, "/") {
+		newKey += "/"
+	}
+	res.To = fmt.Sprintf("s3://%s/%s", m.toBucket, newKey)
+	if pp.Archive != "" {
+		res.To = pp.Archive + "+" + res.To
+	}
+
+	fail := func(err error) *MigrateResult {
+		res.Status = MigrateFailed
+		res.Error = err.Error()
+		return res
+	}
+
+	objects, err := listEntityObjects(ctx, m.src, pp.Host, pp.Path)
+	if err != nil {
+		return fail(err)
+	}
+	if len(objects) == 0 {
+		return fail(fmt.Errorf("no objects found at %s", pathStr))
+	}
+	for _, o := range objects {
+		res.Objects++
+		res.Size += o.Size
+	}
+
+	if m.dryRun {
+		res.Status = MigratePlanned
+		return res
+	}
+
+	for _, o := range objects {
+		dstKey := newKey
+		if strings.HasSuffix(pp.Path, "/") {
+			dstKey = newKey + strings.TrimPrefix(o.Key, pp.Path)
+		}
+		if err := m.copyObject(ctx, pp.Host, o, dstKey); err != nil {
+			return fail(err)
+		}
+	}
+
+	spec, _ := entity["spec"].(map[string]interface{})
+	spec["path"] = res.To
+	delete(entity, "user")
+	body, err := json.Marshal(entity)
+	if err != nil {
+		return fail(err)
+	}
+	err = crud.Update(ctx, crudsvc.UpdateRequest{
+		ResourceRequest: crudsvc.ResourceRequest{Project: project, Resource: endpoint},
+		ID:              res.ID,
+		Body:            body,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("data copied but entity update failed: %w", err))
+	}
+	res.Status = MigrateMigrated
+	return res
+}
+
+// listEntityObjects lists the objects of an entity path: every object under a
+// directory path, or the single object at a file path.
+func listEntityObjects(ctx context.Context, client *s3.Client, bucket string, key string) ([]utils.S3Object, error) {
+	objects, err := utils.ListS3Objects(ctx, client, bucket, key)
+	if err != nil || strings.HasSuffix(key, "/") {
+		return objects, err
+	}
+	var exact []utils.S3Object
+	for _, o := range objects {
+		if o.Key == key {
+			exact = append(exact, o)
+		}
+	}
+	return exact, nil
+}
+
+// copyObject copies one object unless it is already at the destination with
+// the same size, then checks the size of the copy.
+func (m *migration) copyObject(ctx context.Context, srcBucket string, obj utils.S3Object, dstKey string) error {
+	logger := utils.GetGlobalLogger()
+	src := fmt.Sprintf("s3://%s/%s", srcBucket, obj.Key)
+
+	if size, err := utils.HeadS3Object(ctx, m.dst, m.toBucket, dstKey); err == nil && size == obj.Size {
+		logger.Debug(fmt.Sprintf("Already copied: %s", src))
+		return nil
+	}
+
+	copied := false
+	if m.sameStore {
+		err := utils.CopyS3Object(ctx, m.src, srcBucket, obj.Key, m.toBucket, dstKey)
+		if err == nil {
+			copied = true
+		} else {
+			logger.Warn(fmt.Sprintf("Server-side copy of %s failed, streaming instead: %v", src, err))
+		}
+	}
+	if !copied {
+		logger.Info(fmt.Sprintf("Streaming %s (%s)", src, cache.HumanSize(obj.Size)))
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(utils.DownloadS3Object(ctx, m.src, srcBucket, obj.Key, pw))
+		}()
+		err := utils.UploadS3Object(ctx, m.dst, m.toBucket, dstKey, pr, "")
+		pr.CloseWithError(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	size, err := utils.HeadS3Object(ctx, m.dst, m.toBucket, dstKey)
+	if err != nil {
+		return err
+	}
+	if size != obj.Size {
+		return fmt.Errorf("size mismatch for %s: source %d bytes, copy %d bytes", src, obj.Size, size)
+	}
+	return nil
+}
+
+// underPrefix reports whether key lies under the directory prefix.
+func underPrefix(key string, prefix string) bool {
+	return prefix == "" || key == prefix || strings.HasPrefix(key, prefix+"/")
+}
+
+func joinKey(prefix string, rel string) string {
+	if prefix == "" {
+		return rel
+	}
+	return path.Join(prefix, rel)
+}
+
+func printMigrateShort(results []MigrateResult) {
+	if len(results) == 0 {
+		fmt.Println("No entities to migrate.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tNAME\tID\tOBJECTS\tSIZE\tSTATUS\tPATH")
+	for _, r := range results {
+		p := r.To
+		if p == "" {
+			p = r.From
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", r.Resource, r.Name, r.ID, r.Objects, cache.HumanSize(r.Size), r.Status, p)
+	}
+	w.Flush()
+}
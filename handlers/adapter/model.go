@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Model kinds known to the platform registry.
+const (
+	modelKindGeneric     = "model"
+	modelKindMlflow      = "mlflow"
+	modelKindHuggingFace = "huggingface"
+	modelKindSklearn     = "sklearn"
+)
+
+// modelDescriptorFile is the optional local file carrying model metadata.
+const modelDescriptorFile = "model.yaml"
+
+// modelInfo is the metadata inferred from a local model before upload.
+type modelInfo struct {
+	Kind    string
+	Spec    map[string]interface{}
+	Metrics map[string]interface{}
+}
+
+// modelDescriptor is the layout of model.yaml. Every field is optional and
+// overrides what detection inferred.
+type modelDescriptor struct {
+	Kind       string                 `json:"kind"`
+	Framework  string                 `json:"framework"`
+	Algorithm  string                 `json:"algorithm"`
+	Parameters map[string]interface{} `json:"parameters"`
+	Metrics    map[string]interface{} `json:"metrics"`
+}
+
+// mlmodelFile is the subset of an MLflow MLmodel file used for registration.
+type mlmodelFile struct {
+	Flavors   map[string]interface{} `json:"flavors"`
+	Signature map[string]interface{} `json:"signature"`
+	Metadata  struct {
+		Parameters map[string]interface{} `json:"parameters"`
+		Metrics    map[string]interface{} `json:"metrics"`
+	} `json:"metadata"`
+}
+
+// detectModel inspects a model file or directory and returns the kind,
+// framework spec fields, parameters and metrics to register. Detection is
+// best effort: unknown layouts yield a generic model. model.yaml is read
+// only from a directory input: next to a single file it may describe
+// another model.
+func detectModel(input string) (*modelInfo, error) {
+	st, err := os.Stat(input)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access input: %w", err)
+	}
+	info := &modelInfo{Kind: modelKindGeneric, Spec: map[string]interface{}{}}
+
+	switch {
+	case st.IsDir() && exists(filepath.Join(input, "MLmodel")):
+		if err := applyMLmodel(info, filepath.Join(input, "MLmodel")); err != nil {
+			return nil, err
+		}
+	case st.IsDir() && exists(filepath.Join(input, "config.json")):
+		if err := applyHuggingFace(info, input); err != nil {
+			return nil, err
+		}
+	default:
+		switch ext := modelFileExt(input, st.IsDir()); ext {
+		case ".onnx":
+			info.Spec["framework"] = "onnx"
+		case ".pkl", ".pickle", ".joblib":
+			info.Kind = modelKindSklearn
+			info.Spec["framework"] = "sklearn"
+		}
+	}
+
+	if st.IsDir() {
+		if err := applyModelDescriptor(info, filepath.Join(input, modelDescriptorFile)); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+func applyMLmodel(info *modelInfo, path string) error {
+	var m mlmodelFile
+	if err := readYAMLInto(path, &m); err != nil {
+		return fmt.Errorf("invalid MLmodel file: %w", err)
+	}
+	info.Kind = modelKindMlflow
+
+	// python_function is the generic wrapper every flavor exposes: the
+	// framework is the other flavor, if any.
+	var flavors []string
+	for f := range m.Flavors {
+		if f != "python_function" {
+			flavors = append(flavors, f)
+		}
+	}
+	sort.Strings(flavors)
+	if len(flavors) > 0 {
+		info.Spec["framework"] = flavors[0]
+		info.Spec["flavor"] = flavors[0]
+	} else if _, ok := m.Flavors["python_function"]; ok {
+		info.Spec["flavor"] = "python_function"
+	}
+	if len(m.Signature) > 0 {
+		info.Spec["signature"] = m.Signature
+	}
+	if len(m.Metadata.Parameters) > 0 {
+		info.Spec["parameters"] = m.Metadata.Parameters
+	}
+	if len(m.Metadata.Metrics) > 0 {
+		info.Metrics = m.Metadata.Metrics
+	}
+	return nil
+}
+
+func applyHuggingFace(info *modelInfo, dir string) error {
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return err
+	}
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		// Not a transformers config: leave the model generic.
+		return nil
+	}
+	if _, ok := cfg["model_type"]; !ok {
+		if _, ok := cfg["architectures"]; !ok {
+			return nil
+		}
+	}
+
+	info.Kind = modelKindHuggingFace
+	switch {
+	case exists(filepath.Join(dir, "tf_model.h5")):
+		info.Spec["framework"] = "tensorflow"
+	case exists(filepath.Join(dir, "flax_model.msgpack")):
+		info.Spec["framework"] = "flax"
+	default:
+		info.Spec["framework"] = "pytorch"
+	}
+	if base, ok := cfg["_name_or_path"].(string); ok && base != "" {
+		info.Spec["base_model"] = base
+	}
+	if archs, ok := cfg["architectures"].([]interface{}); ok && len(archs) > 0 {
+		if a, ok := archs[0].(string); ok {
+			info.Spec["algorithm"] = a
+		}
+	}
+	return nil
+}
+
+func applyModelDescriptor(info *modelInfo, path string) error {
+	var d modelDescriptor
+	if err := readYAMLInto(path, &d); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("invalid %s: %w", modelDescriptorFile, err)
+	}
+	if d.Kind != "" {
+		info.Kind = d.Kind
+	}
+	if d.Framework != "" {
+		info.Spec["framework"] = d.Framework
+	}
+	if d.Algorithm != "" {
+		info.Spec["algorithm"] = d.Algorithm
+	}
+	if len(d.Parameters) > 0 {
+		info.Spec["parameters"] = d.Parameters
+	}
+	if len(d.Metrics) > 0 {
+		info.Metrics = d.Metrics
+	}
+	return nil
+}
+
+// modelFileExt returns the extension of the model file: the input itself, or
+// the first file with a known model extension in a directory.
+func modelFileExt(input string, isDir bool) string {
+	if !isDir {
+		return strings.ToLower(filepath.Ext(input))
+	}
+	entries, err := os.ReadDir(input)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		switch ext := strings.ToLower(filepath.Ext(e.Name())); ext {
+		case ".onnx", ".pkl", ".pickle", ".joblib":
+			return ext
+		}
+	}
+	return ""
+}
+
+func readYAMLInto(path string, v interface{}) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, v)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,318 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"sigs.k8s.io/yaml"
+
+	"dhcli/handlers/preview"
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// PreviewOutput is the full preview of a dataitem file.
+type PreviewOutput struct {
+	File         string               `json:"file"                    yaml:"file"`
+	Size         int64                `json:"size"                    yaml:"size"`
+	Preview      *preview.Result      `json:"preview"                 yaml:"preview"`
+	StoredSchema []preview.Field      `json:"stored_schema,omitempty" yaml:"stored_schema,omitempty"`
+	Differences  []preview.Difference `json:"differences,omitempty"   yaml:"differences,omitempty"`
+}
+
+// PreviewHandler prints the schema and the first rows of a tabular dataitem,
+// fetching only the bytes it needs through ranged reads.
+func PreviewHandler(env string, output string, project string, name string, resource string, id string, rows int) error {
+	endpoint := utils.TranslateEndpoint(resource)
+
+	utils.CheckUpdateEnvironment()
+	utils.CheckApiLevel(keys.ApiLevelKey, keys.GetMin, keys.GetMax)
+	if err := utils.CheckCredentials(); err != nil {
+		return err
+	}
+
+	if endpoint != "projects" && project == "" {
+		return errors.New("project is mandatory for non-project resources")
+	}
+	if id == "" && name == "" {
+		return errors.New("you must specify id or name")
+	}
+	if rows < 0 {
+		return errors.New("rows must not be negative")
+	}
+
+	ctx := context.Background()
+	entity, err := fetchEntity(ctx, project, endpoint, id, name)
+	if err != nil {
+		return err
+	}
+
+	pathStr := entitySpecPath(entity)
+	pp, err := utils.ParsePath(pathStr)
+	if err != nil {
+		return fmt.Errorf("invalid path in entity: %w", err)
+	}
+
+	var reader preview.RangeReader
+	var file string
+	switch pp.Scheme {
+	case "s3":
+		client, err := utils.NewS3Client(ctx)
+		if err != nil {
+			return err
+		}
+		key, size, err := pickPreviewObject(ctx, client, pp)
+		if err != nil {
+			return err
+		}
+		file = fmt.Sprintf("s3://%s/%s", pp.Host, key)
+		reader = &s3RangeReader{ctx: ctx, client: client, bucket: pp.Host, key: key, size: size}
+	case "http", "https":
+		file = pathStr
+		reader, err = newHTTPRangeReader(ctx, pathStr)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("preview is not supported for %q paths", pp.Scheme)
+	}
+
+	res, err := preview.Preview(reader, preview.DetectFormat(file), rows)
+	if err != nil {
+		return fmt.Errorf("cannot preview %s: %w", file, err)
+	}
+
+	out := PreviewOutput{File: file, Size: reader.Size(), Preview: res, StoredSchema: storedSchema(entity)}
+	if len(out.StoredSchema) > 0 {
+		out.Differences = preview.CompareSchemas(out.StoredSchema, res.Schema)
+	}
+
+	switch utils.TranslateFormat(output) {
+	case "json":
+		b, err := json.MarshalIndent(out, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(out)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		printPreviewShort(out)
+	}
+	return nil
+}
+
+// pickPreviewObject returns the object to preview: the path itself, or the
+// first file with a supported format under a directory path.
+func pickPreviewObject(ctx context.Context, client *s3.Client, pp *utils.ParsedPath) (string, int64, error) {
+	if !strings.HasSuffix(pp.Path, "/") {
+		size, err := utils.HeadS3Object(ctx, client, pp.Host, pp.Path)
+		return pp.Path, size, err
+	}
+	objects, err := utils.ListS3Objects(ctx, client, pp.Host, pp.Path)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, o := range objects {
+		if preview.DetectFormat(o.Key) != "" {
+			return o.Key, o.Size, nil
+		}
+	}
+	return "", 0, fmt.Errorf("no CSV, Parquet or JSON lines file found under s3://%s/%s", pp.Host, pp.Path)
+}
+
+// storedSchema reads the schema recorded on the entity, looking at
+// spec.schema then status.schema. Both frictionless ({"fields": [...]}) and
+// plain field lists are accepted.
+func storedSchema(entity map[string]interface{}) []preview.Field {
+	for _, section := range []string{"spec", "status"} {
+		m, _ := entity[section].(map[string]interface{})
+		raw, ok := m["schema"]
+		if !ok {
+			continue
+		}
+		if s, ok := raw.(map[string]interface{}); ok {
+			raw = s["fields"]
+		}
+		list, _ := raw.([]interface{})
+		var fields []preview.Field
+		for _, f := range list {
+			fm, ok := f.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			fields = append(fields, preview.Field{
+				Name: utils.GetStringValue(fm, "name"),
+				Type: utils.GetStringValue(fm, "type"),
+			})
+		}
+		if len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+func printPreviewShort(out PreviewOutput) {
+	res := out.Preview
+	fmt.Printf("File:   %s\n", out.File)
+	fmt.Printf("Format: %s\n", res.Format)
+	if res.TotalRows > 0 {
+		fmt.Printf("Rows:   %d\n", res.TotalRows)
+	}
+
+	stored := map[string]string{}
+	for _, f := range out.StoredSchema {
+		stored[f.Name] = f.Type
+	}
+	flagged := map[string]bool{}
+	for _, d := range out.Differences {
+		flagged[d.Field] = true
+	}
+
+	fmt.Println("\nSchema:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	if len(out.StoredSchema) > 0 {
+		fmt.Fprintln(w, "\tNAME\tTYPE\tSTORED")
+	} else {
+		fmt.Fprintln(w, "\tNAME\tTYPE")
+	}
+	for _, f := range res.Schema {
+		mark := " "
+		if flagged[f.Name] {
+			mark = "!"
+		}
+		if len(out.StoredSchema) > 0 {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, f.Name, f.Type, stored[f.Name])
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", mark, f.Name, f.Type)
+		}
+	}
+	w.Flush()
+
+	if len(out.Differences) > 0 {
+		fmt.Println("\nSchema differences:")
+		for _, d := range out.Differences {
+			switch d.Issue {
+			case preview.IssueType:
+				fmt.Printf("  ! %s: %s (stored %s, file %s)\n", d.Field, d.Issue, d.Stored, d.Actual)
+			default:
+				fmt.Printf("  ! %s: %s\n", d.Field, d.Issue)
+			}
+		}
+	}
+
+	if len(res.Rows) == 0 {
+		return
+	}
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(res.Columns, "\t"))
+	for _, row := range res.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = previewCell(v)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	if res.Truncated {
+		fmt.Printf("(first %d rows)\n", len(res.Rows))
+	}
+}
+
+func previewCell(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// s3RangeReader reads ranges of an S3 object.
+type s3RangeReader struct {
+	ctx    context.Context
+	client *s3.Client
+	bucket string
+	key    string
+	size   int64
+}
+
+func (r *s3RangeReader) Size() int64 { return r.size }
+
+func (r *s3RangeReader) ReadRange(offset, length int64) ([]byte, error) {
+	return utils.ReadS3Range(r.ctx, r.client, r.bucket, r.key, offset, length)
+}
+
+// httpRangeReader reads ranges of an HTTP resource. Servers ignoring the
+// Range header are tolerated by truncating the response.
+type httpRangeReader struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	size   int64
+}
+
+func newHTTPRangeReader(ctx context.Context, url string) (*httpRangeReader, error) {
+	client := utils.NewHTTPClient(0)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HEAD %s: %s", url, resp.Status)
+	}
+	return &httpRangeReader{ctx: ctx, client: client, url: url, size: resp.ContentLength}, nil
+}
+
+func (r *httpRangeReader) Size() int64 { return r.size }
+
+func (r *httpRangeReader) ReadRange(offset, length int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return io.ReadAll(io.LimitReader(resp.Body, length))
+	case http.StatusOK:
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			return nil, err
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, length))
+	}
+	return nil, fmt.Errorf("GET %s: %s", r.url, resp.Status)
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"dhcli/handlers/cache"
+)
+
+// transferProgress renders a single-line progress indicator on stderr. It is
+// an io.Writer so it can be fed through io.TeeReader; writes may come from the
+// concurrent part uploads of a multipart transfer.
+type transferProgress struct {
+	mu       sync.Mutex
+	total    int64
+	done     int64
+	lastTick time.Time
+}
+
+func newTransferProgress(total int64) *transferProgress {
+	return &transferProgress{total: total}
+}
+
+func (p *transferProgress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += int64(len(b))
+	p.render(false)
+	return len(b), nil
+}
+
+// Finish prints the final state and terminates the line.
+func (p *transferProgress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render(true)
+	fmt.Fprintln(os.Stderr)
+}
+
+func (p *transferProgress) render(force bool) {
+	// throttle to ~10 updates per second
+	if !force && time.Since(p.lastTick) < 100*time.Millisecond {
+		return
+	}
+	p.lastTick = time.Now()
+
+	if p.total > 0 {
+		done := min(p.done, p.total)
+		pct := float64(done) / float64(p.total) * 100
+		fmt.Fprintf(os.Stderr, "\rProgress: %6.2f%% (%s / %s)   ", pct, cache.HumanSize(done), cache.HumanSize(p.total))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\rProgress: %s   ", cache.HumanSize(p.done))
+}
@@ -58,13 +58,18 @@ func RunHandler(env string, project string, functionName string, functionId stri
 		inputSpec = map[string]interface{}{}
 	}
 
+	// defaults pinned by the workspace
+	if ws := utils.CurrentWorkspace(); ws != nil && len(ws.Run) > 0 {
+		inputSpec = utils.MergeDefaults(inputSpec, ws.Run)
+	}
+
 	cfg := config.Config{
 		Core: config.CoreConfig{
 			BaseURL:     viper.GetString(keys.DhCoreEndpoint),
 			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
 			AccessToken: viper.GetString(keys.DhCoreAccessToken),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	svc, err := sdk.NewRunService(context.Background(), cfg)
@@ -28,7 +28,7 @@ func ResolveRunIDByFunctionName(project, functionName, state, action string) (st
 			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
 			AccessToken: viper.GetString(keys.DhCoreAccessToken),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	ctx := context.Background()
@@ -120,7 +120,7 @@ func ResolveRunIDByName(project, name, state, action string) (string, error) {
 			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
 			AccessToken: viper.GetString(keys.DhCoreAccessToken),
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	ctx := context.Background()
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// maxPresignExpiry is the upper bound accepted by SigV4 presigned URLs.
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// ShareLink is a single presigned URL for one file of an entity.
+type ShareLink struct {
+	File    string `json:"file"    yaml:"file"`
+	Size    int64  `json:"size"    yaml:"size"`
+	Method  string `json:"method"  yaml:"method"`
+	URL     string `json:"url"     yaml:"url"`
+	Expires string `json:"expires" yaml:"expires"`
+}
+
+// ShareHandler generates presigned GET (and optionally PUT) URLs for every
+// file stored under the entity's S3 path, using the environment credentials.
+func ShareHandler(env string, output string, project string, name string, resource string, id string, expires string, upload bool) error {
+	endpoint := utils.TranslateEndpoint(resource)
+
+	utils.CheckUpdateEnvironment()
+	utils.CheckApiLevel(keys.ApiLevelKey, keys.GetMin, keys.GetMax)
+	if err := utils.CheckCredentials(); err != nil {
+		return err
+	}
+
+	if endpoint != "projects" && project == "" {
+		return errors.New("project is mandatory for non-project resources")
+	}
+	if id == "" && name == "" {
+		return errors.New("you must specify id or name")
+	}
+
+	ttl, err := time.ParseDuration(expires)
+	if err != nil {
+		return fmt.Errorf("invalid expiry %q: %w", expires, err)
+	}
+	if ttl <= 0 || ttl > maxPresignExpiry {
+		return fmt.Errorf("expiry must be between 1s and %s", maxPresignExpiry)
+	}
+	warnIfCredentialsExpireBefore(ttl)
+
+	ctx := context.Background()
+
+	entity, err := fetchEntity(ctx, project, endpoint, id, name)
+	if err != nil {
+		return err
+	}
+
+	pathStr := entitySpecPath(entity)
+	pp, err := utils.ParsePath(pathStr)
+	if err != nil {
+		return fmt.Errorf("invalid path in entity: %w", err)
+	}
+	if pp.Scheme != "s3" {
+		return fmt.Errorf("only s3 paths can be shared, entity path is %q", pathStr)
+	}
+
+	client, err := utils.NewS3Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	prefix := pp.Path
+	isDir := strings.HasSuffix(prefix, "/")
+	objects, err := utils.ListS3Objects(ctx, client, pp.Host, prefix)
+	if err != nil {
+		return err
+	}
+
+	presigner := s3.NewPresignClient(client)
+	expiresAt := time.Now().Add(ttl).UTC().Format(time.RFC3339)
+
+	var links []ShareLink
+	for _, obj := range objects {
+		if !isDir && obj.Key != prefix {
+			continue
+		}
+		file := pp.Filename
+		if isDir {
+			file = strings.TrimPrefix(obj.Key, prefix)
+		}
+
+		get, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(pp.Host),
+			Key:    aws.String(obj.Key),
+		}, s3.WithPresignExpires(ttl))
+		if err != nil {
+			return fmt.Errorf("failed to presign %s: %w", obj.Key, err)
+		}
+		links = append(links, ShareLink{File: file, Size: obj.Size, Method: get.Method, URL: get.URL, Expires: expiresAt})
+
+		if upload {
+			put, err := presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+				Bucket: aws.String(pp.Host),
+				Key:    aws.String(obj.Key),
+			}, s3.WithPresignExpires(ttl))
+			if err != nil {
+				return fmt.Errorf("failed to presign upload for %s: %w", obj.Key, err)
+			}
+			links = append(links, ShareLink{File: file, Size: obj.Size, Method: put.Method, URL: put.URL, Expires: expiresAt})
+		}
+	}
+	if len(links) == 0 {
+		return fmt.Errorf("no files found at %s", pathStr)
+	}
+
+	switch utils.TranslateFormat(output) {
+	case "json":
+		// Keep '&' in URLs readable instead of \u0026.
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "    ")
+		return enc.Encode(links)
+	case "yaml":
+		b, err := yaml.Marshal(links)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		for _, l := range links {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", l.Method, l.File, l.URL)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+// warnIfCredentialsExpireBefore warns when temporary S3 credentials expire
+// before the requested link lifetime: presigned URLs stop working as soon as
+// the signing credentials do.
+func warnIfCredentialsExpireBefore(ttl time.Duration) {
+	raw := viper.GetString("aws_credentials_expiration")
+	if raw == "" {
+		return
+	}
+	exp, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return
+	}
+	if time.Until(exp) < ttl {
+		utils.GetGlobalLogger().Warn(fmt.Sprintf("S3 credentials expire at %s, links will stop working at that time", exp.Local().Format(time.RFC3339)))
+	}
+}
@@ -6,20 +6,51 @@ package adapter
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
-	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/services/transfer"
-
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/config"
+	"github.com/scc-digitalhub/digitalhub-cli-sdk/sdk/services/transfer"
 
+	"dhcli/handlers/cache"
 	"dhcli/handlers/utils"
 	"dhcli/keys"
 
 	"github.com/spf13/viper"
 )
 
-func UploadHandler(env string, input string, project string, resource string, id string, name string, verbose bool) error {
+// uploadRequest describes a single upload operation.
+type uploadRequest struct {
+	Project  string
+	Resource string
+	ID       string
+	Name     string
+	Input    string
+	Bucket   string
+	Verbose  bool
+	// Archive packs a directory input into a single object of this format.
+	Archive string
+	// InPlace registers the input under a file:// path instead of copying
+	// it to S3.
+	InPlace bool
+
+	// Kind, Spec and Metrics describe the entity: Kind defaults to Resource
+	// for a new one, Spec fields are added next to spec.path, also in an
+	// existing entity of the same kind, Metrics are stored in status.metrics.
+	Kind    string
+	Spec    map[string]interface{}
+	Metrics map[string]interface{}
+}
+
+func UploadHandler(env string, input string, project string, resource string, id string, name string, verbose bool, archive string, inPlace bool) error {
 
 	utils.CheckUpdateEnvironment()
 	utils.CheckApiLevel(keys.ApiLevelKey, keys.LoginMin, keys.LoginMax)
@@ -36,21 +67,22 @@ func UploadHandler(env string, input string, project string, resource string, id
 		return errors.New("project is mandatory for non-project resources")
 	}
 
-	// Traduzione viper -> sdk.Config (retro-compat)
-	cfg := config.Config{
-		Core: config.CoreConfig{
-			BaseURL:     viper.GetString(keys.DhCoreEndpoint),
-			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
-			AccessToken: viper.GetString(keys.DhCoreAccessToken),
-		},
-		S3: config.S3Config{
-			AccessKey:   viper.GetString("aws_access_key_id"),
-			SecretKey:   viper.GetString("aws_secret_access_key"),
-			AccessToken: viper.GetString("aws_session_token"),
-			Region:      viper.GetString("aws_region"),
-			EndpointURL: viper.GetString("aws_endpoint_url"),
-		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+	if inPlace && archive != "" {
+		return errors.New("--in-place cannot be combined with --archive")
+	}
+	if inPlace && id != "" {
+		return errors.New("--in-place registers a new entity and cannot be used with an id")
+	}
+
+	if archive != "" {
+		format, err := utils.NormalizeArchiveFormat(archive)
+		if err != nil {
+			return err
+		}
+		if st, err := os.Stat(input); err == nil && !st.IsDir() {
+			return errors.New("--archive requires a directory input")
+		}
+		archive = format
 	}
 
 	// bucket override da viper, "datalake" di default.
@@ -59,12 +91,15 @@ func UploadHandler(env string, input string, project string, resource string, id
 		bucket = "datalake"
 	}
 
-	svc, err := transfer.NewTransferService(context.Background(), cfg)
-	if err != nil {
-		return fmt.Errorf("sdk init failed: %w", err)
+	// Start with fresh S3 credentials; the client refreshes them again if
+	// they expire while the transfer is running.
+	if !inPlace {
+		if err := utils.EnsureS3Credentials(); err != nil {
+			return err
+		}
 	}
 
-	_, err = svc.Upload(context.Background(), endpoint, transfer.UploadRequest{
+	req := uploadRequest{
 		Project:  project,
 		Resource: resource,
 		ID:       id,
@@ -72,10 +107,461 @@ func UploadHandler(env string, input string, project string, resource string, id
 		Input:    input,
 		Verbose:  verbose,
 		Bucket:   bucket,
-	})
+		Archive:  archive,
+		InPlace:  inPlace,
+	}
+
+	if endpoint == "models" {
+		info, err := detectModel(input)
+		if err != nil {
+			return err
+		}
+		utils.GetGlobalLogger().Info(fmt.Sprintf("Detected model kind %q, framework %q", info.Kind, utils.GetStringValue(info.Spec, "framework")))
+		req.Kind = info.Kind
+		req.Spec = info.Spec
+		req.Metrics = info.Metrics
+	}
+
+	ctx := context.Background()
+	client, err := utils.NewS3Client(ctx)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = uploadEntity(ctx, client, endpoint, req)
+	return err
+}
+
+// uploadEntity creates the entity when no ID is given, moves it through the
+// UPLOADING state, uploads the input to spec.path and marks it READY with the
+// list of uploaded files. It is the only upload path of the CLI: the SDK
+// transfer service is not used, as its S3 client cannot refresh credentials.
+func uploadEntity(ctx context.Context, client *s3.Client, endpoint string, req uploadRequest) (map[string]interface{}, error) {
+	core := config.NewHTTPCore(utils.NewHTTPClient(0), coreConfig().Core)
+
+	st, err := os.Stat(req.Input)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access input: %w", err)
+	}
+
+	runKey, err := currentRunKey(ctx, core, req.Project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve run: %w", err)
+	}
+
+	// 1) create the entity when no ID is given
+	entityID := req.ID
+	if entityID == "" {
+		if req.Name == "" {
+			return nil, errors.New("name is required when creating a new artifact")
+		}
+		entityID = transfer.UUIDv4NoDash()
+
+		specPath := fmt.Sprintf("s3://%s/%s/%s/%s/%s/", req.Bucket, req.Project, req.Resource, req.Name, entityID)
+		switch {
+		case req.InPlace:
+			abs, err := filepath.Abs(req.Input)
+			if err != nil {
+				return nil, fmt.Errorf("cannot resolve input path: %w", err)
+			}
+			specPath = "file://" + filepath.ToSlash(abs)
+			if st.IsDir() {
+				specPath += "/"
+			}
+		case req.Archive != "":
+			specPath = req.Archive + "+" + specPath + filepath.Base(filepath.Clean(req.Input)) + utils.ArchiveExt(req.Archive)
+		case !st.IsDir():
+			specPath += st.Name()
+		}
+
+		kind := req.Kind
+		if kind == "" {
+			kind = req.Resource
+		}
+		spec := map[string]interface{}{}
+		for k, v := range req.Spec {
+			spec[k] = v
+		}
+		spec["path"] = specPath
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"id":      entityID,
+			"project": req.Project,
+			"kind":    kind,
+			"name":    req.Name,
+			"spec":    spec,
+			"status":  map[string]interface{}{"state": "CREATED"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal artifact creation payload: %w", err)
+		}
+		if _, _, err := core.Do(ctx, http.MethodPost, core.BuildURL(req.Project, endpoint, "", nil), payload); err != nil {
+			return nil, fmt.Errorf("failed to create artifact: %w", err)
+		}
+	}
+
+	// 2) retrieve it and check its state
+	body, _, err := core.Do(ctx, http.MethodGet, core.BuildURL(req.Project, endpoint, entityID, nil), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve artifact info: %w", err)
+	}
+	var entity map[string]interface{}
+	if err := json.Unmarshal(body, &entity); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	status, ok := entity["status"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("missing or invalid status field")
+	}
+	if state, _ := status["state"].(string); state != "CREATED" {
+		return nil, fmt.Errorf("artifact is not in CREATED state, current state: %s", state)
+	}
+
+	pathStr := entitySpecPath(entity)
+	pp, err := utils.ParsePath(pathStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path in artifact: %w", err)
+	}
+	if pp.Scheme != "s3" && pp.Scheme != "file" {
+		return nil, fmt.Errorf("upload is not supported for %q paths", pp.Scheme)
+	}
+	if req.Archive != "" && req.Archive != pp.Archive {
+		return nil, fmt.Errorf("entity path %q is not a %s archive path", pathStr, req.Archive)
+	}
+
+	// Directories uploaded to an archive path are packed first; a file input
+	// is taken to be the archive itself.
+	input := req.Input
+	if pp.Archive != "" && st.IsDir() {
+		packed, cleanup, err := packDirectory(req.Input, pp)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		input = packed
+	}
+
+	if runKey != "" {
+		addRelationship(entity, "produced_by", runKey)
+	}
+
+	// an existing entity gets the detected spec fields too, saved with the
+	// status updates below, unless they were detected for another kind
+	if req.ID != "" && len(req.Spec) > 0 {
+		if kind := utils.GetStringValue(entity, "kind"); req.Kind != "" && kind != req.Kind {
+			utils.GetGlobalLogger().Warn(fmt.Sprintf("Detected model kind %q differs from the entity kind %q, keeping its spec", req.Kind, kind))
+		} else {
+			spec, _ := entity["spec"].(map[string]interface{})
+			if spec == nil {
+				spec = map[string]interface{}{}
+			}
+			for k, v := range req.Spec {
+				if k != "path" {
+					spec[k] = v
+				}
+			}
+			entity["spec"] = spec
+		}
+	}
+
+	updateStatus := func(update map[string]interface{}) error {
+		for k, v := range update {
+			status[k] = v
+		}
+		entity["status"] = status
+		payload, err := json.Marshal(entity)
+		if err != nil {
+			return fmt.Errorf("failed to marshal updated artifact: %w", err)
+		}
+		if _, _, err := core.Do(ctx, http.MethodPut, core.BuildURL(req.Project, endpoint, entityID, nil), payload); err != nil {
+			return fmt.Errorf("failed to update artifact status: %w", err)
+		}
+		return nil
+	}
+
+	// 3) UPLOADING → upload → READY
+	if err := updateStatus(map[string]interface{}{"state": "UPLOADING"}); err != nil {
+		return nil, err
+	}
+
+	var files []map[string]interface{}
+	if pp.Scheme == "file" {
+		files, err = copyFiles(pp, input, req.Verbose)
+	} else {
+		files, err = uploadFiles(ctx, client, pp, input, req.Verbose)
+	}
+	if err != nil {
+		_ = updateStatus(map[string]interface{}{"state": "ERROR"})
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+
+	ready := map[string]interface{}{"state": "READY", "files": files}
+	if len(req.Metrics) > 0 {
+		ready["metrics"] = req.Metrics
+	}
+	if err := updateStatus(ready); err != nil {
+		return entity, fmt.Errorf("upload succeeded but failed to update status: %w", err)
+	}
+	return entity, nil
+}
+
+// packDirectory archives dir into a temporary file named after the archive
+// object, so the recorded file name matches spec.path.
+func packDirectory(dir string, pp *utils.ParsedPath) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "dhcli-archive-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	name := pp.Filename
+	if strings.HasSuffix(pp.Path, "/") {
+		name = filepath.Base(filepath.Clean(dir)) + utils.ArchiveExt(pp.Archive)
+	}
+	packed := filepath.Join(tmpDir, name)
+
+	f, err := os.Create(packed)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	utils.GetGlobalLogger().Info(fmt.Sprintf("Packing %s into %s archive ...", dir, pp.Archive))
+	if err := utils.CreateArchive(dir, pp.Archive, f); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return packed, cleanup, nil
+}
+
+// localFile is an input file together with its destination.
+type localFile struct {
+	path string // local source
+	rel  string // path relative to the input root, empty for a single file
+	dest string // object key or destination path
+	info os.FileInfo
+}
+
+// listLocalFiles enumerates the input. For directories every regular file is
+// placed under destRoot; a single file goes to destRoot itself, or inside it
+// when destRoot ends with a slash.
+func listLocalFiles(input string, destRoot string) ([]localFile, int64, error) {
+	st, err := os.Stat(input)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot access input: %w", err)
+	}
+
+	if !st.IsDir() {
+		dest := destRoot
+		if strings.HasSuffix(dest, "/") {
+			dest += st.Name()
+		}
+		return []localFile{{path: input, dest: dest, info: st}}, st.Size(), nil
+	}
+
+	var locals []localFile
+	var totalBytes int64
+	err = filepath.Walk(input, func(p string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		// Skip directories and symlinks: a symlinked directory yields a
+		// non-seekable stream the S3 client cannot retry.
+		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(input, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		locals = append(locals, localFile{path: p, rel: rel, dest: path.Join(destRoot, rel), info: info})
+		totalBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to enumerate local directory: %w", err)
+	}
+	return locals, totalBytes, nil
+}
+
+// fileDescriptor is the status.files entry of an uploaded file.
+func fileDescriptor(lf localFile, contentType string, digest string) map[string]interface{} {
+	return map[string]interface{}{
+		"path":          lf.rel,
+		"name":          lf.info.Name(),
+		"content_type":  contentType,
+		"last_modified": lf.info.ModTime().UTC().Format(http.TimeFormat),
+		"size":          lf.info.Size(),
+		"hash":          digest,
+	}
+}
+
+// uploadFiles uploads a file or a directory tree under the S3 path and returns
+// the file descriptors stored in status.files.
+func uploadFiles(ctx context.Context, client *s3.Client, pp *utils.ParsedPath, input string, verbose bool) ([]map[string]interface{}, error) {
+	logger := utils.GetGlobalLogger()
+
+	locals, totalBytes, err := listLocalFiles(input, pp.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Uploading %s → s3://%s/%s (%d files, %s)", input, pp.Host, pp.Path, len(locals), cache.HumanSize(totalBytes)))
+	progress := newTransferProgress(totalBytes)
+
+	var files []map[string]interface{}
+	for i, lf := range locals {
+		if verbose {
+			logger.Info(fmt.Sprintf("[%d/%d] %s → s3://%s/%s", i+1, len(locals), lf.path, pp.Host, lf.dest))
+		}
+		contentType, digest, err := uploadLocalFile(ctx, client, pp.Host, lf.dest, lf.path, progress)
+		if err != nil {
+			progress.Finish()
+			return nil, err
+		}
+		files = append(files, fileDescriptor(lf, contentType, digest))
+	}
+	progress.Finish()
+	return files, nil
+}
+
+// copyFiles copies a file or a directory tree to a file:// path and returns
+// the file descriptors stored in status.files. Files already at their
+// destination are only hashed.
+func copyFiles(pp *utils.ParsedPath, input string, verbose bool) ([]map[string]interface{}, error) {
+	logger := utils.GetGlobalLogger()
+
+	destRoot := filepath.FromSlash(pp.Path)
+	locals, totalBytes, err := listLocalFiles(input, filepath.ToSlash(destRoot))
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Storing %s → file://%s (%d files, %s)", input, pp.Path, len(locals), cache.HumanSize(totalBytes)))
+	progress := newTransferProgress(totalBytes)
+
+	var files []map[string]interface{}
+	for i, lf := range locals {
+		dest := filepath.FromSlash(lf.dest)
+		if verbose {
+			logger.Info(fmt.Sprintf("[%d/%d] %s → %s", i+1, len(locals), lf.path, dest))
+		}
+		contentType, digest, err := copyLocalFile(lf.path, dest, progress)
+		if err != nil {
+			progress.Finish()
+			return nil, err
+		}
+		files = append(files, fileDescriptor(lf, contentType, digest))
+	}
+	progress.Finish()
+	return files, nil
+}
+
+// copyLocalFile copies src to dest, or only reads it when both are the same
+// file, and returns its content type and digest.
+func copyLocalFile(src string, dest string, progress io.Writer) (string, string, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 512)
+	n, _ := f.Read(header)
+	contentType := http.DetectContentType(header[:n])
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", "", fmt.Errorf("seek error: %w", err)
+	}
+
+	hasher := utils.NewFileHasher()
+	body := io.TeeReader(f, io.MultiWriter(progress, hasher))
+
+	srcInfo, _ := f.Stat()
+	if destInfo, err := os.Stat(dest); err == nil && os.SameFile(srcInfo, destInfo) {
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return "", "", err
+		}
+		return contentType, utils.FormatDigest(hasher), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", "", err
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := io.Copy(out, body); err != nil {
+		out.Close()
+		return "", "", fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err := out.Close(); err != nil {
+		return "", "", err
+	}
+	return contentType, utils.FormatDigest(hasher), nil
+}
+
+// uploadLocalFile streams a local file to S3 and returns its detected
+// content type and its digest, computed on the bytes actually sent.
+func uploadLocalFile(ctx context.Context, client *s3.Client, bucket, key, path string, progress io.Writer) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 512)
+	n, _ := f.Read(header)
+	contentType := http.DetectContentType(header[:n])
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", "", fmt.Errorf("seek error: %w", err)
+	}
+
+	hasher := utils.NewFileHasher()
+	body := io.TeeReader(f, io.MultiWriter(progress, hasher))
+	if err := utils.UploadS3Object(ctx, client, bucket, key, body, contentType); err != nil {
+		return "", "", err
+	}
+	return contentType, utils.FormatDigest(hasher), nil
+}
+
+// currentRunKey returns the key of the run referenced by run_id, if any: when
+// the CLI runs inside a job, uploaded entities are linked to that run.
+func currentRunKey(ctx context.Context, core config.CoreHTTP, project string) (string, error) {
+	runID := viper.GetString(transfer.RunId)
+	if runID == "" {
+		return "", nil
+	}
+	body, _, err := core.Do(ctx, http.MethodGet, core.BuildURL(project, utils.TranslateEndpoint("run"), runID, nil), nil)
+	if err != nil {
+		return "", err
+	}
+	var run map[string]interface{}
+	if err := json.Unmarshal(body, &run); err != nil {
+		return "", err
+	}
+	if key := utils.GetStringValue(run, "key"); key != "" {
+		return key, nil
+	}
+	return "", fmt.Errorf("run key not found in response")
+}
+
+// addRelationship appends a relationship to metadata.relationships.
+func addRelationship(entity map[string]interface{}, relType, dest string) {
+	meta, ok := entity["metadata"].(map[string]interface{})
+	if !ok {
+		meta = map[string]interface{}{}
+		entity["metadata"] = meta
+	}
+	rels, _ := meta["relationships"].([]interface{})
+	meta["relationships"] = append(rels, map[string]interface{}{
+		"type": relType,
+		"dest": dest,
+	})
 }
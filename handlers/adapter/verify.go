@@ -0,0 +1,276 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// Verification outcomes for a single file.
+const (
+	VerifyOK       = "ok"
+	VerifyMissing  = "missing"
+	VerifyExtra    = "extra"
+	VerifyMismatch = "mismatch"
+)
+
+// ErrDrift is returned by VerifyHandler when the stored files do not match
+// the manifest recorded in status.files.
+var ErrDrift = errors.New("content drift detected")
+
+// VerifyResult is the outcome of the check of a single file.
+type VerifyResult struct {
+	File     string `json:"file"               yaml:"file"`
+	Status   string `json:"status"             yaml:"status"`
+	Expected string `json:"expected,omitempty" yaml:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"   yaml:"actual,omitempty"`
+}
+
+// manifestEntry is a file as recorded in status.files.
+type manifestEntry struct {
+	size int64
+	hash string
+}
+
+// VerifyHandler compares the files recorded in the entity status with either
+// the remote objects (re-hashed) or a local copy in localDir.
+func VerifyHandler(env string, output string, project string, name string, resource string, id string, localDir string) error {
+	endpoint := utils.TranslateEndpoint(resource)
+
+	utils.CheckUpdateEnvironment()
+	utils.CheckApiLevel(keys.ApiLevelKey, keys.GetMin, keys.GetMax)
+	if err := utils.CheckCredentials(); err != nil {
+		return err
+	}
+
+	if endpoint != "projects" && project == "" {
+		return errors.New("project is mandatory for non-project resources")
+	}
+	if id == "" && name == "" {
+		return errors.New("you must specify id or name")
+	}
+
+	ctx := context.Background()
+	entity, err := fetchEntity(ctx, project, endpoint, id, name)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := entityManifest(entity)
+	if err != nil {
+		return err
+	}
+
+	var actual map[string]manifestEntry
+	if localDir != "" {
+		actual, err = hashLocalFiles(localDir, manifest)
+	} else {
+		actual, err = hashRemoteFiles(ctx, entity)
+	}
+	if err != nil {
+		return err
+	}
+
+	results := compareManifest(manifest, actual)
+	if err := printVerifyResults(results, utils.TranslateFormat(output)); err != nil {
+		return err
+	}
+
+	drift := 0
+	for _, r := range results {
+		if r.Status != VerifyOK {
+			drift++
+		}
+	}
+	if drift > 0 {
+		return fmt.Errorf("%w: %d of %d files", ErrDrift, drift, len(results))
+	}
+	return nil
+}
+
+// entityManifest indexes status.files by relative path. Single-file entities
+// record an empty path, in which case the file name is used.
+func entityManifest(entity map[string]interface{}) (map[string]manifestEntry, error) {
+	status, _ := entity["status"].(map[string]interface{})
+	files, _ := status["files"].([]interface{})
+	if len(files) == 0 {
+		return nil, errors.New("entity has no files recorded in status.files")
+	}
+
+	manifest := make(map[string]manifestEntry, len(files))
+	for _, f := range files {
+		m, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		file := utils.GetStringValue(m, "path")
+		if file == "" {
+			file = utils.GetStringValue(m, "name")
+		}
+		size, _ := m["size"].(float64) // JSON numbers decode as float64
+		manifest[file] = manifestEntry{
+			size: int64(size),
+			hash: utils.GetStringValue(m, "hash"),
+		}
+	}
+	return manifest, nil
+}
+
+// hashLocalFiles hashes every file under dir. When dir is a regular file it
+// stands for the single file of the manifest.
+func hashLocalFiles(dir string, manifest map[string]manifestEntry) (map[string]manifestEntry, error) {
+	st, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]manifestEntry{}
+	if !st.IsDir() {
+		if len(manifest) != 1 {
+			return nil, fmt.Errorf("%s is a file but the entity has %d files", dir, len(manifest))
+		}
+		digest, err := utils.HashFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		for file := range manifest {
+			out[file] = manifestEntry{size: st.Size(), hash: digest}
+		}
+		return out, nil
+	}
+
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		digest, err := utils.HashFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", p, err)
+		}
+		out[filepath.ToSlash(rel)] = manifestEntry{size: info.Size(), hash: digest}
+		return nil
+	})
+	return out, err
+}
+
+// hashRemoteFiles streams every object under the entity path through the
+// hasher.
+func hashRemoteFiles(ctx context.Context, entity map[string]interface{}) (map[string]manifestEntry, error) {
+	pathStr := entitySpecPath(entity)
+	pp, err := utils.ParsePath(pathStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path in entity: %w", err)
+	}
+	if pp.Scheme != "s3" {
+		return nil, fmt.Errorf("remote verification supports only s3 paths, entity path is %q (use -d to verify a local copy)", pathStr)
+	}
+
+	client, err := utils.NewS3Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := pp.Path
+	isDir := strings.HasSuffix(prefix, "/")
+	objects, err := utils.ListS3Objects(ctx, client, pp.Host, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := utils.GetGlobalLogger()
+	out := map[string]manifestEntry{}
+	for _, obj := range objects {
+		file := pp.Filename
+		if isDir {
+			file = strings.TrimPrefix(obj.Key, prefix)
+		} else if obj.Key != prefix {
+			continue
+		}
+
+		logger.Info(fmt.Sprintf("Hashing s3://%s/%s", pp.Host, obj.Key))
+		hasher := utils.NewFileHasher()
+		if err := utils.DownloadS3Object(ctx, client, pp.Host, obj.Key, hasher); err != nil {
+			return nil, err
+		}
+		out[file] = manifestEntry{size: obj.Size, hash: utils.FormatDigest(hasher)}
+	}
+	return out, nil
+}
+
+// compareManifest classifies every file of both sides. Entries recorded
+// without a hash (uploaded before digests were tracked) are compared by size.
+func compareManifest(expected, actual map[string]manifestEntry) []VerifyResult {
+	var results []VerifyResult
+	for file, exp := range expected {
+		act, ok := actual[file]
+		switch {
+		case !ok:
+			results = append(results, VerifyResult{File: file, Status: VerifyMissing, Expected: exp.hash})
+		case exp.hash != "" && exp.hash != act.hash:
+			results = append(results, VerifyResult{File: file, Status: VerifyMismatch, Expected: exp.hash, Actual: act.hash})
+		case exp.hash == "" && exp.size != act.size:
+			results = append(results, VerifyResult{
+				File:     file,
+				Status:   VerifyMismatch,
+				Expected: fmt.Sprintf("%d bytes", exp.size),
+				Actual:   fmt.Sprintf("%d bytes", act.size),
+			})
+		default:
+			results = append(results, VerifyResult{File: file, Status: VerifyOK, Expected: exp.hash, Actual: act.hash})
+		}
+	}
+	for file, act := range actual {
+		if _, ok := expected[file]; !ok {
+			results = append(results, VerifyResult{File: file, Status: VerifyExtra, Actual: act.hash})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].File < results[j].File })
+	return results
+}
+
+func printVerifyResults(results []VerifyResult, format string) error {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(results, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "STATUS\tFILE")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\n", r.Status, r.File)
+		}
+		w.Flush()
+	}
+	return nil
+}
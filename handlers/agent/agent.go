@@ -0,0 +1,273 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	stateFileName  = ".dhcli-agent.json"
+	socketDirName  = ".dhcli-agent"
+	socketFileName = "agent.sock"
+	logFileName    = ".dhcli-agent.log"
+
+	// maxSocketPath keeps the socket path within the sun_path limit of
+	// every platform.
+	maxSocketPath = 100
+
+	startTimeout = 10 * time.Second
+	stopTimeout  = 5 * time.Second
+)
+
+var logger = utils.GetGlobalLogger()
+
+// errNotRunning is returned when no agent answers for the INI file in use.
+var errNotRunning = errors.New("the agent is not running")
+
+// State describes a running agent. It is written next to the INI file and
+// readable by its owner only, as it holds the secret of the HTTP endpoint.
+type State struct {
+	Pid         int    `json:"pid"`
+	Socket      string `json:"socket"`
+	URL         string `json:"url,omitempty"`
+	Secret      string `json:"secret,omitempty"`
+	Environment string `json:"environment"`
+	Identity    string `json:"identity"`
+	StartedAt   string `json:"started_at"`
+}
+
+// Options configure the agent started by StartHandler.
+type Options struct {
+	// Foreground serves in the current process instead of a background one.
+	Foreground bool
+	// HTTP enables the localhost endpoint, on HTTPPort or any free port.
+	HTTP     bool
+	HTTPPort int
+}
+
+// StartHandler starts an agent serving the credentials of the current
+// environment and identity, and prints the shell variables locating it.
+func StartHandler(opts Options) error {
+	if st, err := runningAgent(); err == nil {
+		return fmt.Errorf("the agent is already running (pid %d)", st.Pid)
+	}
+	if opts.Foreground {
+		return serve(opts)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{"agent", "start", "--foreground",
+		"--env", viper.GetString(keys.CurrentEnvironment),
+		"--identity", utils.ActiveIdentity()}
+	if opts.HTTP {
+		args = append(args, "--http", "--http-port", strconv.Itoa(opts.HTTPPort))
+	}
+	if logger.IsVerbose() {
+		args = append(args, "--verbose")
+	}
+
+	logPath := filepath.Join(stateDir(), logFileName)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot start the agent: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.After(startTimeout)
+	for {
+		if st, err := runningAgent(); err == nil && st.Pid == cmd.Process.Pid {
+			printExports(st)
+			logger.Success(fmt.Sprintf("Agent started (pid %d) for '%s' as '%s'", st.Pid, st.Environment, st.Identity))
+			return nil
+		}
+		select {
+		case err := <-exited:
+			return fmt.Errorf("the agent exited (%v), see %s", err, logPath)
+		case <-deadline:
+			return fmt.Errorf("the agent did not answer within %s, see %s", startTimeout, logPath)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// StatusHandler prints the state of the agent.
+func StatusHandler() error {
+	st, err := runningAgent()
+	if err != nil {
+		return err
+	}
+	var status map[string]string
+	if err := call(st, http.MethodGet, "/v1/status", &status); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	row := func(label string, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", label, value)
+		}
+	}
+	row("Pid", strconv.Itoa(st.Pid))
+	row("Environment", st.Environment)
+	row("Identity", st.Identity)
+	row("Started", st.StartedAt)
+	row("Socket", st.Socket)
+	row("URL", st.URL)
+	row("Token expires", status[keys.DhCoreExpiresAt])
+	row("S3 credentials expire", status["aws_credentials_expiration"])
+	return w.Flush()
+}
+
+// StopHandler asks the agent to shut down and waits for it to exit.
+func StopHandler() error {
+	st, err := runningAgent()
+	if err != nil {
+		return err
+	}
+	if err := call(st, http.MethodPost, "/v1/shutdown", nil); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(stopTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := runningAgent(); err != nil {
+			logger.Success(fmt.Sprintf("Agent stopped (pid %d)", st.Pid))
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("the agent (pid %d) did not stop within %s", st.Pid, stopTimeout)
+}
+
+// runningAgent returns the state of the agent serving the INI file in use,
+// once it answered on its socket. A state left by an agent that is gone is
+// removed.
+func runningAgent() (State, error) {
+	var st State
+	b, err := os.ReadFile(statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return st, errNotRunning
+	}
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("invalid agent state %s: %w", statePath(), err)
+	}
+	if err := call(st, http.MethodGet, "/v1/status", nil); err != nil {
+		logger.Info(fmt.Sprintf("Removing the state of a stale agent (pid %d)", st.Pid))
+		_ = os.Remove(statePath())
+		return st, errNotRunning
+	}
+	return st, nil
+}
+
+// call sends a request to the agent over its socket and decodes the JSON
+// answer into out, when given.
+func call(st State, method string, path string, out interface{}) error {
+	client := &http.Client{
+		Timeout: 2 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", st.Socket)
+			},
+		},
+	}
+	req, err := http.NewRequest(method, "http://agent"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("agent answered %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// printExports prints the agent location as shell assignments, to be
+// evaluated with eval "$(dhcli agent start)".
+func printExports(st State) {
+	vars := [][2]string{{"DHCLI_AGENT_SOCKET", st.Socket}}
+	if st.URL != "" {
+		vars = append(vars, [2]string{"DHCLI_AGENT_URL", st.URL}, [2]string{"DHCLI_AGENT_SECRET", st.Secret})
+	}
+	for _, v := range vars {
+		fmt.Printf("%s=%s; export %s;\n", v[0], shellQuote(v[1]), v[0])
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// stateDir is where the agent files of the INI file in use live.
+func stateDir() string {
+	return filepath.Dir(utils.GetIniPath())
+}
+
+func statePath() string {
+	return filepath.Join(stateDir(), stateFileName)
+}
+
+// socketDir returns the directory of the socket, reachable by its owner only
+// so that the socket never is, whatever the umask: next to the INI file, or
+// a new one in the temporary directory when the socket path would be too
+// long. Clients find the socket through the state file.
+func socketDir() (string, error) {
+	dir := filepath.Join(stateDir(), socketDirName)
+	if len(filepath.Join(dir, socketFileName)) > maxSocketPath {
+		return os.MkdirTemp("", "dhcli-agent-")
+	}
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", err
+	}
+	// the directory may be left by an earlier agent, or be something else
+	st, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !st.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	return dir, os.Chmod(dir, 0o700)
+}
@@ -0,0 +1,15 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package agent
+
+import "syscall"
+
+// detachedProcAttr starts the agent in its own session, so that it outlives
+// the terminal it was started from.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
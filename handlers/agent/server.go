@@ -0,0 +1,255 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+)
+
+// server serves the credentials of one environment and identity. Renewals
+// go through the token source, so concurrent requests trigger one refresh.
+type server struct {
+	tokens   *utils.TokenSource
+	state    State
+	shutdown context.CancelFunc
+}
+
+// serve runs the agent in the current process until it is stopped or
+// interrupted.
+func serve(opts Options) error {
+	tokens := utils.CurrentTokenSource()
+	token, err := tokens.Token()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no credentials for '%s': log in first", viper.GetString(keys.CurrentEnvironment))
+	}
+
+	dir, err := socketDir()
+	if err != nil {
+		return fmt.Errorf("cannot create the socket directory: %w", err)
+	}
+	defer os.Remove(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &server{
+		tokens: tokens,
+		state: State{
+			Pid:         os.Getpid(),
+			Socket:      filepath.Join(dir, socketFileName),
+			Environment: viper.GetString(keys.CurrentEnvironment),
+			Identity:    utils.ActiveIdentity(),
+			StartedAt:   time.Now().UTC().Format(time.RFC3339),
+		},
+		shutdown: cancel,
+	}
+	mux := s.routes()
+
+	// runningAgent checked that no agent answers on a socket left behind
+	_ = os.Remove(s.state.Socket)
+	ln, err := net.Listen("unix", s.state.Socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(s.state.Socket)
+	servers := []*http.Server{{Handler: mux, ReadHeaderTimeout: 10 * time.Second}}
+	listeners := []net.Listener{ln}
+
+	if opts.HTTP {
+		tl, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(opts.HTTPPort)))
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			ln.Close()
+			tl.Close()
+			return err
+		}
+		s.state.Secret = hex.EncodeToString(secret)
+		s.state.URL = "http://" + tl.Addr().String()
+		servers = append(servers, &http.Server{Handler: requireSecret(s.state.Secret, mux), ReadHeaderTimeout: 10 * time.Second})
+		listeners = append(listeners, tl)
+	}
+
+	errCh := make(chan error, len(servers))
+	for i, srv := range servers {
+		go func(srv *http.Server, l net.Listener) {
+			if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}(srv, listeners[i])
+	}
+
+	if err := writeState(s.state); err != nil {
+		return err
+	}
+	defer removeState(s.state.Pid)
+	logger.Success(fmt.Sprintf("Agent serving '%s' as '%s' on %s", s.state.Environment, s.state.Identity, s.state.Socket))
+
+	go tokens.KeepFresh(ctx)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	var serveErr error
+	select {
+	case sig := <-sigCh:
+		logger.Info("Received signal: " + sig.String())
+	case <-ctx.Done():
+	case serveErr = <-errCh:
+	}
+	cancel()
+
+	shutdownCtx, done := context.WithTimeout(context.Background(), stopTimeout)
+	defer done()
+	for _, srv := range servers {
+		_ = srv.Shutdown(shutdownCtx)
+	}
+	return serveErr
+}
+
+func (s *server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/token", s.handleToken)
+	mux.HandleFunc("GET /v1/s3", s.handleS3)
+	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("POST /v1/shutdown", s.handleShutdown)
+	return mux
+}
+
+// handleToken answers the access token of the environment, renewed when it
+// is about to expire, with the endpoint it is valid for.
+func (s *server) handleToken(w http.ResponseWriter, r *http.Request) {
+	token, err := s.tokens.Token()
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+	expiresAt := s.tokens.Get(keys.DhCoreExpiresAt)
+	if token == "" || (err != nil && expired(expiresAt)) {
+		writeError(w, http.StatusServiceUnavailable, "no valid access token: log in again")
+		return
+	}
+	writeJSON(w, map[string]string{
+		"environment":          s.state.Environment,
+		"identity":             s.state.Identity,
+		keys.DhCoreEndpoint:    s.tokens.Get(keys.DhCoreEndpoint),
+		keys.DhCoreApiVersion:  s.tokens.Get(keys.DhCoreApiVersion),
+		keys.DhCoreAccessToken: token,
+		keys.DhCoreExpiresAt:   expiresAt,
+	})
+}
+
+// handleS3 answers the pass-through S3 credentials, renewed when they are
+// about to expire.
+func (s *server) handleS3(w http.ResponseWriter, r *http.Request) {
+	values, err := s.tokens.S3Credentials()
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+	if values["aws_access_key_id"] == "" {
+		writeError(w, http.StatusNotFound, "no S3 credentials for this environment")
+		return
+	}
+	if err != nil && expired(values["aws_credentials_expiration"]) {
+		writeError(w, http.StatusServiceUnavailable, "S3 credentials expired and cannot be renewed: log in again")
+		return
+	}
+	writeJSON(w, values)
+}
+
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{
+		"pid":                        strconv.Itoa(s.state.Pid),
+		"environment":                s.state.Environment,
+		"identity":                   s.state.Identity,
+		"started_at":                 s.state.StartedAt,
+		keys.DhCoreExpiresAt:         s.tokens.Get(keys.DhCoreExpiresAt),
+		"aws_credentials_expiration": s.tokens.Get("aws_credentials_expiration"),
+	})
+}
+
+func (s *server) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{"status": "stopping"})
+	s.shutdown()
+}
+
+// requireSecret guards the HTTP endpoint, reachable by every local user,
+// with the secret of the session as a bearer token.
+func requireSecret(secret string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + secret)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid agent secret")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func expired(expiresAt string) bool {
+	t, err := time.Parse(time.RFC3339, expiresAt)
+	return err == nil && time.Now().After(t)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeState publishes the state of the agent, readable by its owner only.
+func writeState(st State) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := statePath() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, statePath())
+}
+
+// removeState removes the state file, unless another agent replaced it.
+func removeState(pid int) {
+	b, err := os.ReadFile(statePath())
+	if err != nil {
+		return
+	}
+	var st State
+	if json.Unmarshal(b, &st) == nil && st.Pid == pid {
+		_ = os.Remove(statePath())
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// ==========================
+// DEVICE AUTHORIZATION FLOW
+// ==========================
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// deviceAuthResponse is the device authorization response (RFC 8628 §3.2).
+type deviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// oauthError is the error body of the token endpoint (RFC 6749 §5.2).
+type oauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// DeviceLoginHandler performs the OAuth2 device authorization grant
+// (RFC 8628): the user completes the login on any device with a browser while
+// the CLI polls the token endpoint. No local callback server is needed, so it
+// works over SSH and inside remote containers.
+func DeviceLoginHandler() error {
+	utils.CheckUpdateEnvironment()
+	utils.CheckApiLevel(keys.ApiLevelKey, keys.LoginMin, keys.LoginMax)
+
+	deviceURL := viper.GetString(keys.OAuth2DeviceEndpoint)
+	if deviceURL == "" {
+		return fmt.Errorf("%s not configured: the provider does not support the device flow", keys.OAuth2DeviceEndpoint)
+	}
+	tokenURL := viper.GetString(keys.OAuth2TokenEndpoint)
+	if tokenURL == "" {
+		return fmt.Errorf("oauth2_token_endpoint not configured")
+	}
+	clientID := viper.GetString(keys.DhCoreClientId)
+	if clientID == "" {
+		return fmt.Errorf("dhcore_client_id not configured")
+	}
+
+	client := utils.NewHTTPClient(15 * time.Second)
+
+	v := url.Values{"client_id": {clientID}}
+	if scope := requestedScope(); scope != "" {
+		v.Set("scope", scope)
+	}
+	resp, err := client.PostForm(deviceURL, v)
+	if err != nil {
+		return fmt.Errorf("device authorization request failed: %w", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("device authorization error: %s - %s", resp.Status, string(body))
+	}
+
+	var da deviceAuthResponse
+	if err := json.Unmarshal(body, &da); err != nil {
+		return fmt.Errorf("failed to parse device authorization response: %w", err)
+	}
+	if da.DeviceCode == "" || da.UserCode == "" || da.VerificationURI == "" {
+		return errors.New("incomplete device authorization response")
+	}
+
+	fmt.Println("────────────────────────────────────────────────────────────")
+	fmt.Println("  To log in, open the following URL on any device:         ")
+	fmt.Println("────────────────────────────────────────────────────────────")
+	fmt.Println(da.VerificationURI)
+	fmt.Println()
+	fmt.Printf("  and enter the code:  %s\n", da.UserCode)
+	if da.VerificationURIComplete != "" {
+		fmt.Println()
+		fmt.Println("  or open directly:")
+		fmt.Println(da.VerificationURIComplete)
+	}
+	fmt.Println("────────────────────────────────────────────────────────────")
+
+	token, err := pollDeviceToken(client, tokenURL, clientID, da)
+	if err != nil {
+		return err
+	}
+
+	clearClientCredentials()
+	credKeys, err := utils.ApplyTokenResponse(token)
+	if err != nil {
+		return fmt.Errorf("failed to apply token response: %w", err)
+	}
+	credKeys = append(credKeys, keys.CredentialsList)
+	if err := utils.PersistCurrentEnv(credKeys); err != nil {
+		logger.Error(fmt.Sprintf("persist error: %v", err))
+	}
+
+	logger.Success("Login successful (device authorization)")
+	return nil
+}
+
+// pollDeviceToken polls the token endpoint until the user approves or denies
+// the request, or the device code expires.
+func pollDeviceToken(client *http.Client, tokenURL, clientID string, da deviceAuthResponse) ([]byte, error) {
+	interval := time.Duration(da.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiresIn := time.Duration(da.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 10 * time.Minute
+	}
+	deadline := time.Now().Add(expiresIn)
+
+	v := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {da.DeviceCode},
+		"client_id":   {clientID},
+	}
+
+	logger.Info("Waiting for the login to be approved ...")
+	for {
+		time.Sleep(interval)
+		if time.Now().After(deadline) {
+			return nil, errors.New("the device code expired before the login was approved, please try again")
+		}
+
+		resp, err := client.PostForm(tokenURL, v)
+		if err != nil {
+			// transient network errors are retried until the code expires
+			logger.Warn(fmt.Sprintf("token request failed: %v", err))
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			return body, nil
+		}
+
+		var oe oauthError
+		_ = json.Unmarshal(body, &oe)
+		switch oe.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, errors.New("the login request was denied")
+		case "expired_token":
+			return nil, errors.New("the device code expired before the login was approved, please try again")
+		default:
+			return nil, fmt.Errorf("token error: %s - %s", resp.Status, string(body))
+		}
+	}
+}
@@ -85,6 +85,7 @@ func LoginHandler() error {
 	}
 
 	// Process token response: prefix standard tokens, pass through dynamic credentials.
+	clearClientCredentials()
 	credKeys, err := utils.ApplyTokenResponse(res.TokenJSON)
 	if err != nil {
 		logger.Error(fmt.Sprintf("token parse error: %v", err))
@@ -127,10 +128,7 @@ func PatLoginHandler(pat string) error {
 		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
 	}
 
-	client := utils.GetDebugHTTPClient()
-	if client == nil {
-		client = &http.Client{Timeout: 15 * time.Second}
-	}
+	client := utils.NewHTTPClient(15 * time.Second)
 
 	resp, err := client.PostForm(tokenURL, v)
 	if err != nil {
@@ -143,6 +141,7 @@ func PatLoginHandler(pat string) error {
 		return fmt.Errorf("token exchange error: %s - %s", resp.Status, string(body))
 	}
 
+	clearClientCredentials()
 	credKeys, err := utils.ApplyTokenResponse(body)
 	if err != nil {
 		return fmt.Errorf("failed to apply token response: %w", err)
@@ -156,6 +155,33 @@ func PatLoginHandler(pat string) error {
 	return nil
 }
 
+// ==========================
+// CLIENT CREDENTIALS
+// ==========================
+
+// ClientCredentialsLoginHandler logs in as a confidential OAuth2 client
+// (service account) with the client_credentials grant. The secret is read
+// from secretFile, or from DHCORE_SERVICE_CLIENT_SECRET when no file is given.
+func ClientCredentialsLoginHandler(clientID string, secretFile string) error {
+	utils.CheckUpdateEnvironment()
+	utils.CheckApiLevel(keys.ApiLevelKey, keys.LoginMin, keys.LoginMax)
+
+	if err := utils.ClientCredentialsLogin(clientID, secretFile); err != nil {
+		return err
+	}
+
+	logger.Success("Login successful (client credentials)")
+	return nil
+}
+
+// clearClientCredentials drops the client credentials marker, so an
+// interactive login is not renewed with a previous service account.
+func clearClientCredentials() {
+	if viper.GetString(keys.DhCoreGrantType) != "" {
+		viper.Set(keys.DhCoreGrantType, "")
+	}
+}
+
 // ==========================
 // PKCE
 // ==========================
@@ -356,11 +382,7 @@ func exchangeAuthCode(tokenURL, clientID, verifier, redirectURI, code string) []
 		"redirect_uri":  {redirectURI},
 	}
 
-	// Use debug HTTP client if available, otherwise use default
-	client := utils.GetDebugHTTPClient()
-	if client == nil {
-		client = &http.Client{Timeout: 15 * time.Second}
-	}
+	client := utils.NewHTTPClient(15 * time.Second)
 
 	resp, err := client.PostForm(tokenURL, v)
 	if err != nil {
@@ -382,7 +404,10 @@ func exchangeAuthCode(tokenURL, clientID, verifier, redirectURI, code string) []
 // ==========================
 // AUTH URL BUILDER
 // ==========================
-func buildAuthURL(chal, state, redirectURI string) (string, error) {
+
+// requestedScope returns the space-separated scopes advertised by the
+// provider (oauth2_scopes_supported).
+func requestedScope() string {
 	raw := viper.GetString(keys.OAuth2ScopesSupported)
 
 	var scopes []string
@@ -398,7 +423,11 @@ func buildAuthURL(chal, state, redirectURI string) (string, error) {
 		}
 	}
 
-	scope := strings.Join(scopes, " ")
+	return strings.Join(scopes, " ")
+}
+
+func buildAuthURL(chal, state, redirectURI string) (string, error) {
+	scope := requestedScope()
 
 	v := url.Values{
 		"response_type":         {"code"},
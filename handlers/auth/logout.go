@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/ini.v1"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// ==========================
+// LOGOUT
+// ==========================
+
+// LogoutHandler ends the session of the current environment and identity, or
+// of every environment and identity holding credentials when all is set.
+// Tokens are revoked when the provider advertises a revocation endpoint
+// (RFC 7009), the OIDC session is ended on request, and the stored
+// credentials are removed in any case.
+func LogoutHandler(all bool, endSession bool) error {
+	unlock, err := utils.LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg := utils.LoadIni(false)
+	current := utils.CredentialSection(viper.GetString(keys.CurrentEnvironment))
+
+	var sections []*ini.Section
+	if all {
+		for _, sec := range cfg.Sections() {
+			if sec.HasKey(keys.CredentialsList) || sec.HasKey(keys.DhCoreAccessToken) {
+				sections = append(sections, sec)
+			}
+		}
+	} else {
+		name := current
+		if _, _, ok := utils.SplitIdentitySection(name); !ok && !cfg.HasSection(name) {
+			name = ini.DefaultSection
+		}
+		if cfg.HasSection(name) {
+			sections = append(sections, cfg.Section(name))
+		}
+	}
+
+	client := utils.NewHTTPClient(15 * time.Second)
+
+	var done []string
+	for _, sec := range sections {
+		get := sessionValues(cfg, sec)
+		revokeTokens(client, get)
+		if endSession {
+			endOIDCSession(client, get)
+		}
+
+		removed := utils.ClearSectionCredentials(sec)
+		if sec.Name() == current || (!all && sec.Name() == ini.DefaultSection) {
+			for _, k := range removed {
+				viper.Set(k, "")
+			}
+		}
+		if len(removed) > 0 {
+			done = append(done, sec.Name())
+		}
+	}
+
+	if len(done) == 0 {
+		logger.Info("No stored credentials, nothing to do.")
+		return nil
+	}
+	if err := utils.SaveIniFile(cfg, utils.GetIniPath()); err != nil {
+		return fmt.Errorf("failed to update ini file: %w", err)
+	}
+	for _, name := range done {
+		logger.Success(fmt.Sprintf("Logged out of '%s'", name))
+	}
+	return nil
+}
+
+// sessionValues returns a lookup of the values of an INI section merged over
+// [DEFAULT], with secret store references resolved. The section of an
+// identity is merged over its environment, credentials excepted.
+func sessionValues(cfg *ini.File, sec *ini.Section) func(string) string {
+	def := cfg.Section(ini.DefaultSection)
+	var env *ini.Section
+	if name, _, ok := utils.SplitIdentitySection(sec.Name()); ok && cfg.HasSection(name) {
+		env = cfg.Section(name)
+	}
+	return func(key string) string {
+		var raw string
+		if sec.HasKey(key) {
+			raw = sec.Key(key).String()
+		} else if env != nil && !utils.IsIdentityKey(key) && env.HasKey(key) {
+			raw = env.Key(key).String()
+		} else if def.HasKey(key) {
+			raw = def.Key(key).String()
+		}
+		v, err := utils.ResolveSecretRef(raw)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("%s: %v", key, err))
+			return ""
+		}
+		return v
+	}
+}
+
+// revokeTokens revokes the refresh and access tokens of a session. Failures
+// are reported but do not prevent the local logout.
+func revokeTokens(client *http.Client, get func(string) string) {
+	endpoint := get(keys.OAuth2RevocationEndpoint)
+	if endpoint == "" {
+		logger.Info("The provider does not advertise a revocation endpoint, tokens are only removed locally")
+		return
+	}
+	clientID := get(keys.DhCoreClientId)
+	if get(keys.DhCoreGrantType) == utils.GrantClientCredentials {
+		clientID = get(keys.DhCoreServiceClientId)
+	}
+
+	// the refresh token goes first: revoking it usually drops its access tokens too
+	for _, t := range []struct{ key, hint string }{
+		{keys.DhCoreRefreshToken, "refresh_token"},
+		{keys.DhCoreAccessToken, "access_token"},
+	} {
+		token := get(t.key)
+		if token == "" {
+			continue
+		}
+		if err := revokeToken(client, endpoint, clientID, token, t.hint); err != nil {
+			logger.Warn(fmt.Sprintf("Cannot revoke %s: %v", t.hint, err))
+		}
+	}
+}
+
+func revokeToken(client *http.Client, endpoint, clientID, token, hint string) error {
+	v := url.Values{
+		"token":           {token},
+		"token_type_hint": {hint},
+	}
+	if clientID != "" {
+		v.Set("client_id", clientID)
+	}
+	resp, err := client.PostForm(endpoint, v)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// the server answers 200 for invalid or already revoked tokens as well
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s - %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// endOIDCSession calls the end_session_endpoint (OpenID Connect RP-Initiated
+// Logout) to terminate the session at the provider.
+func endOIDCSession(client *http.Client, get func(string) string) {
+	endpoint := get(keys.OAuth2EndSessionEndpoint)
+	if endpoint == "" {
+		logger.Warn("The provider does not advertise an end session endpoint")
+		return
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Invalid end session endpoint: %v", err))
+		return
+	}
+	q := u.Query()
+	if idToken := get(keys.DhCoreIdToken); idToken != "" {
+		q.Set("id_token_hint", idToken)
+	}
+	if clientID := get(keys.DhCoreClientId); clientID != "" {
+		q.Set("client_id", clientID)
+	}
+	u.RawQuery = q.Encode()
+
+	// the provider redirects to its own logout page, which is not needed here
+	c := *client
+	c.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := c.Get(u.String())
+	if err != nil {
+		logger.Warn(fmt.Sprintf("End session request failed: %v", err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		logger.Warn(fmt.Sprintf("End session request failed: %s", resp.Status))
+	}
+}
@@ -19,6 +19,23 @@ import (
 )
 
 func RefreshHandler() error {
+	// Hold the INI lock so a concurrent automatic refresh does not race with
+	// this one for the same refresh token.
+	unlock, err := utils.LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if utils.IsClientCredentials() {
+		// confidential clients get no refresh token: repeat the grant
+		if err := utils.DoClientCredentials(); err != nil {
+			return err
+		}
+		log.Printf("Token refreshed.\n")
+		return nil
+	}
+
 	if viper.GetString(keys.DhCoreRefreshToken) == "" {
 		return fmt.Errorf("no refresh token available – please log in first")
 	}
@@ -45,11 +62,7 @@ func RefreshHandler() error {
 		data.Set("scope", strings.Join(scopes, " "))
 	}
 
-	// Use debug HTTP client if available, otherwise use default
-	client := utils.GetDebugHTTPClient()
-	if client == nil {
-		client = &http.Client{}
-	}
+	client := utils.NewHTTPClient(0)
 
 	resp, err := client.Post(viper.GetString(keys.OAuth2TokenEndpoint), "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
 	if err != nil {
@@ -0,0 +1,340 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+)
+
+// ==========================
+// WHOAMI
+// ==========================
+
+// Identity is what the CLI knows about the logged-in user, as decoded from
+// the access and ID tokens and optionally confirmed by the provider.
+type Identity struct {
+	Environment   string                 `json:"environment"`
+	Identity      string                 `json:"identity"`
+	Endpoint      string                 `json:"endpoint"`
+	Subject       string                 `json:"subject,omitempty"`
+	Username      string                 `json:"username,omitempty"`
+	Issuer        string                 `json:"issuer,omitempty"`
+	Audience      []string               `json:"audience,omitempty"`
+	Scopes        []string               `json:"scopes,omitempty"`
+	Roles         []string               `json:"roles,omitempty"`
+	Projects      map[string][]string    `json:"projects,omitempty"`
+	ExpiresAt     string                 `json:"expires_at,omitempty"`
+	Expiry        string                 `json:"expiry,omitempty"`
+	AccessToken   map[string]interface{} `json:"access_token_claims,omitempty"`
+	IdToken       map[string]interface{} `json:"id_token_claims,omitempty"`
+	UserInfo      map[string]interface{} `json:"userinfo,omitempty"`
+	Introspection map[string]interface{} `json:"introspection,omitempty"`
+}
+
+// WhoAmIHandler prints the identity behind the current credentials. With
+// userinfo and introspect the provider endpoints advertised in the oauth2_*
+// configuration are queried as well.
+func WhoAmIHandler(output string, userinfo bool, introspect bool) error {
+	utils.CheckUpdateEnvironment()
+	if err := utils.CheckCredentials(); err != nil {
+		return err
+	}
+
+	accessToken := viper.GetString(keys.DhCoreAccessToken)
+	if accessToken == "" {
+		return errors.New("no access token available – please log in first")
+	}
+
+	id := Identity{
+		Environment: viper.GetString(keys.CurrentEnvironment),
+		Identity:    utils.ActiveIdentity(),
+		Endpoint:    viper.GetString(keys.DhCoreEndpoint),
+	}
+
+	// Opaque tokens (e.g. personal access tokens) carry no claims.
+	var err error
+	if id.AccessToken, err = decodeJWT(accessToken); err != nil {
+		logger.Warn(fmt.Sprintf("The access token is not a JWT: %v", err))
+	}
+	if idToken := viper.GetString(keys.DhCoreIdToken); idToken != "" {
+		if id.IdToken, err = decodeJWT(idToken); err != nil {
+			logger.Warn(fmt.Sprintf("The ID token is not a JWT: %v", err))
+		}
+	}
+
+	client := utils.NewHTTPClient(15 * time.Second)
+	if userinfo {
+		if id.UserInfo, err = fetchUserInfo(client, accessToken); err != nil {
+			logger.Error(fmt.Sprintf("Userinfo request failed: %v", err))
+		}
+	}
+	if introspect {
+		if id.Introspection, err = introspectToken(client, accessToken); err != nil {
+			logger.Error(fmt.Sprintf("Introspection request failed: %v", err))
+		}
+	}
+
+	// Claims are looked up in the access token first, then in the other sources.
+	sources := []map[string]interface{}{id.AccessToken, id.Introspection, id.IdToken, id.UserInfo}
+	id.Subject = claimString(sources, "sub")
+	id.Username = claimString(sources, "preferred_username", "username", "email", "name")
+	id.Issuer = claimString(sources, "iss")
+	id.Audience = claimList(sources, "aud")
+	id.Scopes = claimList(sources, "scope", "scp")
+	id.Roles, id.Projects = splitAuthorities(claimList(sources, "authorities", "roles", "groups"))
+	if ra, ok := id.AccessToken["realm_access"].(map[string]interface{}); ok {
+		id.Roles = append(id.Roles, toStrings(ra["roles"])...)
+	}
+
+	if exp, ok := claimTime(sources, "exp"); ok {
+		id.ExpiresAt = exp.UTC().Format(time.RFC3339)
+		id.Expiry = countdown(exp)
+	} else if v := viper.GetString(keys.DhCoreExpiresAt); v != "" {
+		if exp, err := time.Parse(time.RFC3339, v); err == nil {
+			id.ExpiresAt = v
+			id.Expiry = countdown(exp)
+		}
+	}
+
+	return printIdentity(id, utils.TranslateFormat(output))
+}
+
+func printIdentity(id Identity, format string) error {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(id, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(id)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		row := func(label string, value string) {
+			if value != "" {
+				fmt.Fprintf(w, "%s:\t%s\n", label, value)
+			}
+		}
+		row("Environment", id.Environment)
+		row("Identity", id.Identity)
+		row("Endpoint", id.Endpoint)
+		row("Subject", id.Subject)
+		row("Username", id.Username)
+		row("Issuer", id.Issuer)
+		row("Audience", strings.Join(id.Audience, ", "))
+		row("Scopes", strings.Join(id.Scopes, " "))
+		row("Roles", strings.Join(id.Roles, ", "))
+
+		projects := make([]string, 0, len(id.Projects))
+		for p := range id.Projects {
+			projects = append(projects, p)
+		}
+		sort.Strings(projects)
+		for i, p := range projects {
+			label := ""
+			if i == 0 {
+				label = "Projects:"
+			}
+			fmt.Fprintf(w, "%s\t%s: %s\n", label, p, strings.Join(id.Projects[p], ", "))
+		}
+
+		if id.ExpiresAt != "" {
+			row("Expires", fmt.Sprintf("%s (%s)", id.ExpiresAt, id.Expiry))
+		}
+		printClaims(w, "Userinfo", id.UserInfo)
+		printClaims(w, "Introspection", id.Introspection)
+		return w.Flush()
+	}
+	return nil
+}
+
+func printClaims(w io.Writer, title string, claims map[string]interface{}) {
+	if len(claims) == 0 {
+		return
+	}
+	names := make([]string, 0, len(claims))
+	for k := range claims {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "%s:\t\n", title)
+	for _, k := range names {
+		fmt.Fprintf(w, "  %s\t%v\n", k, claims[k])
+	}
+}
+
+// decodeJWT returns the claims of a JWT without verifying its signature: the
+// token is only inspected, the server remains the authority.
+func decodeJWT(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload encoding: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	return claims, nil
+}
+
+// fetchUserInfo calls the OIDC userinfo endpoint with the access token.
+func fetchUserInfo(client *http.Client, accessToken string) (map[string]interface{}, error) {
+	endpoint := viper.GetString(keys.OAuth2UserinfoEndpoint)
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s not configured", keys.OAuth2UserinfoEndpoint)
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeClaimsResponse(resp)
+}
+
+// introspectToken asks the provider about the access token (RFC 7662).
+func introspectToken(client *http.Client, accessToken string) (map[string]interface{}, error) {
+	endpoint := viper.GetString(keys.OAuth2IntrospectionEndpoint)
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s not configured", keys.OAuth2IntrospectionEndpoint)
+	}
+	v := url.Values{
+		"token":           {accessToken},
+		"token_type_hint": {"access_token"},
+	}
+	clientID := viper.GetString(keys.DhCoreClientId)
+	if utils.IsClientCredentials() {
+		clientID = viper.GetString(keys.DhCoreServiceClientId)
+	}
+	if clientID != "" {
+		v.Set("client_id", clientID)
+	}
+	resp, err := client.PostForm(endpoint, v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeClaimsResponse(resp)
+}
+
+func decodeClaimsResponse(resp *http.Response) (map[string]interface{}, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s - %s", resp.Status, string(body))
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return claims, nil
+}
+
+// claimString returns the first non-empty string found for any of the names.
+func claimString(sources []map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		for _, src := range sources {
+			if s, ok := src[name].(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// claimList returns the first claim found for any of the names as a list;
+// space-separated strings (e.g. scope) are split.
+func claimList(sources []map[string]interface{}, names ...string) []string {
+	for _, name := range names {
+		for _, src := range sources {
+			if v, ok := src[name]; ok {
+				if l := toStrings(v); len(l) > 0 {
+					return l
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func claimTime(sources []map[string]interface{}, name string) (time.Time, bool) {
+	for _, src := range sources {
+		if f, ok := src[name].(float64); ok {
+			return time.Unix(int64(f), 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toStrings(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return strings.Fields(t)
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			out = append(out, fmt.Sprint(e))
+		}
+		return out
+	}
+	return nil
+}
+
+// splitAuthorities separates global roles from project permissions, which
+// the platform encodes as "<project>:<role>".
+func splitAuthorities(authorities []string) ([]string, map[string][]string) {
+	var roles []string
+	projects := map[string][]string{}
+	for _, a := range authorities {
+		if project, role, ok := strings.Cut(a, ":"); ok && project != "" && role != "" {
+			projects[project] = append(projects[project], role)
+			continue
+		}
+		roles = append(roles, a)
+	}
+	if len(projects) == 0 {
+		projects = nil
+	}
+	return roles, projects
+}
+
+func countdown(exp time.Time) string {
+	d := time.Until(exp).Round(time.Second)
+	if d < 0 {
+		return fmt.Sprintf("expired %s ago", -d)
+	}
+	return fmt.Sprintf("in %s", d)
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"dhcli/handlers/utils"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ListHandler prints the cache content.
+func ListHandler(output string) error {
+	store, err := Open()
+	if err != nil {
+		return err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	return printEntries(entries, utils.TranslateFormat(output), store.Root())
+}
+
+// PruneHandler evicts entries by age and/or total size.
+func PruneHandler(maxSize string, olderThan string, output string) error {
+	if maxSize == "" && olderThan == "" {
+		return fmt.Errorf("specify --max-size and/or --older-than")
+	}
+
+	limit := int64(-1)
+	if maxSize != "" {
+		n, err := ParseSize(maxSize)
+		if err != nil {
+			return err
+		}
+		limit = n
+	}
+
+	var age time.Duration
+	if olderThan != "" {
+		d, err := time.ParseDuration(olderThan)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", olderThan, err)
+		}
+		age = d
+	}
+
+	store, err := Open()
+	if err != nil {
+		return err
+	}
+	removed, err := store.Prune(limit, age)
+	if err != nil {
+		return err
+	}
+
+	format := utils.TranslateFormat(output)
+	if format != "short" {
+		return printEntries(removed, format, store.Root())
+	}
+	var freed int64
+	for _, e := range removed {
+		freed += e.Size
+	}
+	log.Printf("Evicted %d entries, freed %s.\n", len(removed), HumanSize(freed))
+	return nil
+}
+
+// ClearHandler removes every cached object.
+func ClearHandler() error {
+	store, err := Open()
+	if err != nil {
+		return err
+	}
+	if err := store.Clear(); err != nil {
+		return err
+	}
+	log.Printf("Cache at %s cleared.\n", store.Root())
+	return nil
+}
+
+func printEntries(entries []Entry, format string, root string) error {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(entries, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		if len(entries) == 0 {
+			fmt.Println("Cache is empty.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "ENTITY\tOBJECT\tETAG\tSIZE\tLAST USED")
+		var total int64
+		for _, e := range entries {
+			total += e.Size
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.EntityKey, e.Object, e.ETag, HumanSize(e.Size), e.LastUsed)
+		}
+		w.Flush()
+		fmt.Printf("\n%d entries, %s in %s\n", len(entries), HumanSize(total), root)
+	}
+	return nil
+}
+
+// ParseSize parses sizes such as "500MB", "10GiB" or "1024" (bytes).
+// Decimal (KB, MB, GB, TB) and binary (KiB, MiB, GiB, TiB) units are accepted.
+func ParseSize(s string) (int64, error) {
+	str := strings.TrimSpace(strings.ToUpper(s))
+	units := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+		{"B", 1},
+	}
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(str, u.suffix) {
+			mult = u.mult
+			str = strings.TrimSpace(strings.TrimSuffix(str, u.suffix))
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// HumanSize formats a byte count using binary units.
+func HumanSize(n int64) string {
+	const (
+		KB = 1024
+		MB = 1024 * KB
+		GB = 1024 * MB
+	)
+	switch {
+	case n >= GB:
+		return fmt.Sprintf("%.2f GB", float64(n)/float64(GB))
+	case n >= MB:
+		return fmt.Sprintf("%.2f MB", float64(n)/float64(MB))
+	case n >= KB:
+		return fmt.Sprintf("%.2f KB", float64(n)/float64(KB))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cache implements the local download cache. Objects are stored once
+// per (entity key, object URI, ETag) triple and copied into download
+// destinations, so that editing a downloaded file never alters the cache.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const objectsDir = "objects"
+
+// Entry is the metadata stored next to every cached blob.
+type Entry struct {
+	Hash      string `json:"hash"       yaml:"hash"`
+	EntityKey string `json:"entity_key" yaml:"entity_key"`
+	Object    string `json:"object"     yaml:"object"`
+	ETag      string `json:"etag"       yaml:"etag"`
+	Size      int64  `json:"size"       yaml:"size"`
+	Created   string `json:"created"    yaml:"created"`
+	LastUsed  string `json:"last_used"  yaml:"last_used"`
+}
+
+// Store is a cache rooted at a directory, addressing every blob by the
+// entity key, object URI and ETag it was stored for.
+type Store struct {
+	root string
+}
+
+// Dir returns the cache root: DHCLI_CACHE_DIR if set, otherwise
+// <user cache dir>/dhcli (honours XDG_CACHE_HOME on Linux).
+func Dir() (string, error) {
+	if d := os.Getenv("DHCLI_CACHE_DIR"); d != "" {
+		return d, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(base, "dhcli"), nil
+}
+
+// Open returns the store at the default cache directory.
+func Open() (*Store, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{root: dir}, nil
+}
+
+// Root returns the directory backing the store.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// hashFor computes the address of an object version from its entity key,
+// URI and ETag.
+func hashFor(entityKey, object, etag string) string {
+	h := sha256.Sum256([]byte(entityKey + "\n" + object + "\n" + etag))
+	return hex.EncodeToString(h[:])
+}
+
+func (s *Store) blobPath(hash string) string {
+	return filepath.Join(s.root, objectsDir, hash[:2], hash)
+}
+
+func (s *Store) metaPath(hash string) string {
+	return s.blobPath(hash) + ".json"
+}
+
+// Lookup returns the path of the cached blob for the given object version.
+// Entries whose blob is missing or has the wrong size are treated as misses.
+func (s *Store) Lookup(entityKey, object, etag string) (string, bool) {
+	if etag == "" {
+		return "", false
+	}
+	hash := hashFor(entityKey, object, etag)
+	entry, err := s.readEntry(hash)
+	if err != nil {
+		return "", false
+	}
+	blob := s.blobPath(hash)
+	st, err := os.Stat(blob)
+	if err != nil || st.Size() != entry.Size {
+		return "", false
+	}
+	entry.LastUsed = time.Now().UTC().Format(time.RFC3339Nano)
+	_ = s.writeEntry(entry)
+	return blob, true
+}
+
+// Put stores a new object version, filling the blob through fill, and returns
+// the path of the cached blob. The blob is written to a temporary file first
+// so a failed transfer never leaves a partial entry behind.
+func (s *Store) Put(entityKey, object, etag string, fill func(w io.Writer) error) (string, error) {
+	hash := hashFor(entityKey, object, etag)
+	blob := s.blobPath(hash)
+	if err := os.MkdirAll(filepath.Dir(blob), 0o755); err != nil {
+		return "", fmt.Errorf("cannot create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(blob), hash+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("cannot create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := fill(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	st, err := os.Stat(tmp.Name())
+	if err != nil {
+		return "", err
+	}
+	// Blobs are read-only, so that nothing rewrites them in place.
+	if err := os.Chmod(tmp.Name(), 0o444); err != nil {
+		return "", err
+	}
+	// a read-only blob cannot be replaced by a rename everywhere
+	if err := os.Remove(blob); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("cannot replace cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), blob); err != nil {
+		return "", fmt.Errorf("cannot store cache file: %w", err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	entry := Entry{
+		Hash:      hash,
+		EntityKey: entityKey,
+		Object:    object,
+		ETag:      etag,
+		Size:      st.Size(),
+		Created:   now,
+		LastUsed:  now,
+	}
+	if err := s.writeEntry(entry); err != nil {
+		return "", err
+	}
+	return blob, nil
+}
+
+// Materialize copies the cached blob to target, replacing any existing file.
+// The target is a new, writable file: it never shares its inode with the
+// cache.
+func Materialize(blob, target string) error {
+	if dir := filepath.Dir(target); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	in, err := os.Open(blob)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// List returns all cache entries, most recently used first.
+func (s *Store) List() ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(filepath.Join(s.root, objectsDir), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		entry, err := s.readEntry(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			return nil // skip unreadable metadata
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return lastUsed(entries[i]).After(lastUsed(entries[j]))
+	})
+	return entries, nil
+}
+
+// Remove deletes a single entry and its blob.
+func (s *Store) Remove(hash string) error {
+	if err := os.Remove(s.blobPath(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(s.metaPath(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Prune evicts entries unused for longer than maxAge (when > 0), then evicts
+// least recently used entries until the total size is at most maxSize (when
+// >= 0). It returns the evicted entries.
+func (s *Store) Prune(maxSize int64, maxAge time.Duration) ([]Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Entry
+	var kept []Entry
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		if maxAge > 0 && lastUsed(e).Before(cutoff) {
+			if err := s.Remove(e.Hash); err != nil {
+				return removed, err
+			}
+			removed = append(removed, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	if maxSize >= 0 {
+		var total int64
+		for _, e := range kept {
+			total += e.Size
+		}
+		// kept is ordered most recently used first: evict from the tail.
+		for i := len(kept) - 1; i >= 0 && total > maxSize; i-- {
+			if err := s.Remove(kept[i].Hash); err != nil {
+				return removed, err
+			}
+			total -= kept[i].Size
+			removed = append(removed, kept[i])
+		}
+	}
+	return removed, nil
+}
+
+// Clear removes the whole cache content.
+func (s *Store) Clear() error {
+	return os.RemoveAll(filepath.Join(s.root, objectsDir))
+}
+
+func lastUsed(e Entry) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, e.LastUsed)
+	return t
+}
+
+func (s *Store) readEntry(hash string) (Entry, error) {
+	var entry Entry
+	b, err := os.ReadFile(s.metaPath(hash))
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(b, &entry)
+	return entry, err
+}
+
+func (s *Store) writeEntry(entry Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.metaPath(entry.Hash), b, 0o644)
+}
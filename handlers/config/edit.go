@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dhcli/handlers/secrets"
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+)
+
+// managedKeys are maintained by dhcli itself and cannot be edited.
+var managedKeys = map[string]string{
+	keys.CurrentEnvironment: "use 'dhcli use <environment>'",
+	keys.CredentialsList:    "use --credential when setting a key",
+	keys.CurrentIdentity:    "use 'dhcli use <environment> --as <identity>'",
+}
+
+// ConfigSetHandler sets a key of the current environment section. Unknown
+// keys need force; credential keys need credential and are added to
+// credentials_list, so they follow the configured secret store.
+func ConfigSetHandler(key string, value string, credential bool, force bool) error {
+	key, err := checkEditableKey(key, credential, force)
+	if err != nil {
+		return err
+	}
+	if err := validateValue(key, value); err != nil {
+		return err
+	}
+
+	additional := []string{key}
+	if credential {
+		list := utils.SplitCSV(viper.GetString(keys.CredentialsList))
+		if !slices.Contains(list, key) {
+			list = append(list, key)
+			viper.Set(keys.CredentialsList, strings.Join(list, ","))
+		}
+		additional = append(additional, keys.CredentialsList)
+	}
+	viper.Set(key, value)
+
+	env := currentEnvName()
+	if err := utils.PersistToIni(utils.GetIniPath(), env, additional); err != nil {
+		return err
+	}
+	section := env
+	if credential || utils.IsIdentityKey(key) {
+		section = utils.CredentialSection(env)
+	}
+	utils.GetGlobalLogger().Success(fmt.Sprintf("%s set in [%s]", key, section))
+	return nil
+}
+
+// ConfigUnsetHandler removes a key from the current environment section.
+func ConfigUnsetHandler(key string, credential bool) error {
+	key, err := checkEditableKey(key, credential, true)
+	if err != nil {
+		return err
+	}
+	env := currentEnvName()
+	if credential || utils.IsIdentityKey(key) {
+		// credentials belong to the identity in use
+		env = utils.CredentialSection(env)
+	}
+	if err := utils.DeleteIniKeys(utils.GetIniPath(), env, []string{key}); err != nil {
+		return err
+	}
+	viper.Set(key, "")
+	utils.GetGlobalLogger().Success(fmt.Sprintf("%s removed from [%s]", key, env))
+	return nil
+}
+
+// ConfigGetHandler prints the effective value of a key, after environment
+// variables and the workspace file are applied.
+func ConfigGetHandler(key string, credential bool) error {
+	key = normalizeKey(key)
+	if isCredentialKey(key) && !credential {
+		return fmt.Errorf("%s is a credential: pass --credential to print it", key)
+	}
+	if !viper.IsSet(key) {
+		return fmt.Errorf("%s is not set", key)
+	}
+	fmt.Println(viper.GetString(key))
+	return nil
+}
+
+// checkEditableKey normalizes a key and checks it may be written.
+func checkEditableKey(key string, credential bool, force bool) (string, error) {
+	key = normalizeKey(key)
+	if key == "" || strings.ContainsAny(key, " \t=[]") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	if hint, ok := managedKeys[key]; ok {
+		return "", fmt.Errorf("%s is managed by dhcli: %s", key, hint)
+	}
+	if isCredentialKey(key) {
+		if !credential {
+			return "", fmt.Errorf("%s is a credential: pass --credential to change it", key)
+		}
+		return key, nil
+	}
+	if credential {
+		return key, nil
+	}
+	if !keys.ConfigKeys[key] && !inCurrentSection(key) && !force {
+		return "", fmt.Errorf("unknown key %s: pass --force to set it anyway", key)
+	}
+	return key, nil
+}
+
+// validateValue rejects values that cannot work for well-known keys.
+func validateValue(key string, value string) error {
+	switch {
+	case value == "":
+		return nil
+	case key == keys.SecretStore:
+		_, err := secrets.Normalize(value)
+		return err
+	case strings.HasSuffix(key, "_endpoint") || strings.HasSuffix(key, "_url") || key == keys.DhCoreIssuer || key == keys.DhCoreProxy || key == keys.HTTPProxy:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s must be an absolute URL", key)
+		}
+	case key == keys.TLSInsecureSkipVerify:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+	}
+	return nil
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func isCredentialKey(key string) bool {
+	return keys.CredentialKeys[key] || credentialKeySet()[key]
+}
+
+// inCurrentSection reports whether the key already exists in the INI section
+// of the current environment, e.g. a value from the well-known configuration.
+func inCurrentSection(key string) bool {
+	cfg := utils.LoadIni(true)
+	env := currentEnvName()
+	return cfg.HasSection(env) && cfg.Section(env).HasKey(key)
+}
+
+func currentEnvName() string {
+	if env := viper.GetString(keys.CurrentEnvironment); env != "" {
+		return env
+	}
+	return "default"
+}
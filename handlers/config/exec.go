@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+)
+
+// execEnvPrefixes are the config and credential keys exported to the child.
+var execEnvPrefixes = []string{"dhcore_", "aws_", "db_"}
+
+// execRefreshInterval is how often the token file is checked with --refresh.
+const execRefreshInterval = 30 * time.Second
+
+// ExecHandler runs a command with the configuration and credentials of the
+// current environment in its environment, and returns the exit code of the
+// command. Signals received by dhcli are forwarded to the child. With
+// refresh the access token is also kept up to date in the file named by
+// DHCORE_ACCESS_TOKEN_FILE for as long as the child runs.
+func ExecHandler(project string, refresh bool, args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, errors.New("no command given")
+	}
+	utils.CheckUpdateEnvironment()
+	if err := utils.CheckCredentials(); err != nil {
+		return 1, err
+	}
+	if err := utils.EnsureS3Credentials(); err != nil {
+		return 1, err
+	}
+
+	env := execEnvironment(project)
+
+	var tokenFile string
+	if refresh {
+		dir, err := os.MkdirTemp("", "dhcli-exec-")
+		if err != nil {
+			return 1, err
+		}
+		defer os.RemoveAll(dir)
+		tokenFile = filepath.Join(dir, "token")
+		if err := writeTokenFile(tokenFile); err != nil {
+			return 1, err
+		}
+		env = append(env, "DHCORE_ACCESS_TOKEN_FILE="+tokenFile)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(sigCh)
+
+	if err := cmd.Start(); err != nil {
+		return 127, fmt.Errorf("cannot start %s: %w", args[0], err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		var ticker <-chan time.Time
+		if refresh {
+			t := time.NewTicker(execRefreshInterval)
+			defer t.Stop()
+			ticker = t.C
+		}
+		for {
+			select {
+			case sig := <-sigCh:
+				// not every signal can be delivered on every platform
+				_ = cmd.Process.Signal(sig)
+			case <-ticker:
+				refreshTokenFile(tokenFile)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return 128 + int(ws.Signal()), nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+// execEnvironment returns the DHCORE_*, AWS_* and DB_* variables of the
+// current environment, plus PROJECT_NAME when a project is given.
+func execEnvironment(project string) []string {
+	entries := getConfigEntriesByProvider("", "short")
+	for k, v := range getCredentialEntriesByProvider("", "short") {
+		entries[k] = v
+	}
+	// keys not stored in the section, e.g. the endpoint coming from DHCORE_ENDPOINT
+	for _, k := range []string{keys.DhCoreEndpoint, keys.DhCoreAccessToken} {
+		if _, ok := entries[k]; !ok && viper.GetString(k) != "" {
+			entries[k] = viper.GetString(k)
+		}
+	}
+	if region := entries["aws_region"]; region != "" {
+		entries["aws_default_region"] = region
+	}
+
+	var env []string
+	for k, v := range entries {
+		lower := strings.ToLower(k)
+		for _, p := range execEnvPrefixes {
+			if strings.HasPrefix(lower, p) {
+				env = append(env, strings.ToUpper(lower)+"="+v)
+				break
+			}
+		}
+	}
+	if project != "" {
+		env = append(env, "PROJECT_NAME="+project)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// refreshTokenFile renews the token when it is about to expire and rewrites
+// the token file. Failures are reported and retried on the next tick.
+func refreshTokenFile(path string) {
+	if expiresAt, err := time.Parse(time.RFC3339, viper.GetString(keys.DhCoreExpiresAt)); err == nil &&
+		time.Until(expiresAt) > 5*time.Minute+execRefreshInterval {
+		return
+	}
+	if err := utils.CheckCredentials(); err != nil {
+		utils.GetGlobalLogger().Warn(fmt.Sprintf("Token refresh failed: %v", err))
+		return
+	}
+	if err := writeTokenFile(path); err != nil {
+		utils.GetGlobalLogger().Warn(fmt.Sprintf("Cannot update the token file: %v", err))
+	}
+}
+
+// writeTokenFile replaces the token file atomically, so the child never reads
+// a partial token.
+func writeTokenFile(path string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(viper.GetString(keys.DhCoreAccessToken)), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
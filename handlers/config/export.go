@@ -0,0 +1,300 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+	"gopkg.in/ini.v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ExportFormats lists the formats accepted by CredentialsExportHandler.
+var ExportFormats = []string{"env", "dotenv", "aws-process", "aws-profile", "docker-env", "k8s-secret"}
+
+// CredentialsExportHandler prints the credentials of the current environment
+// in a format other tools understand: shell exports, env files, the AWS
+// credential_process JSON, an AWS profile or a Kubernetes Secret.
+func CredentialsExportHandler(format string, provider string) error {
+	utils.CheckUpdateEnvironment()
+	if err := utils.CheckCredentials(); err != nil {
+		return err
+	}
+
+	switch format {
+	case "aws-process":
+		if err := utils.EnsureS3Credentials(); err != nil {
+			return err
+		}
+		return printAwsProcess()
+	case "aws-profile":
+		if err := utils.EnsureS3Credentials(); err != nil {
+			return err
+		}
+		return printAwsProfile()
+	}
+
+	entries := getCredentialEntriesByProvider(provider, "short")
+	names := make([]string, 0, len(entries))
+	for k := range entries {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	switch format {
+	case "env":
+		for _, k := range names {
+			fmt.Printf("export %s=%s\n", strings.ToUpper(k), shellQuote(entries[k]))
+		}
+	case "dotenv":
+		for _, k := range names {
+			fmt.Printf("%s=%s\n", strings.ToUpper(k), dotenvQuote(entries[k]))
+		}
+	case "docker-env":
+		// docker env files take values verbatim, without quoting or escapes
+		for _, k := range names {
+			if strings.ContainsAny(entries[k], "\r\n") {
+				utils.GetGlobalLogger().Warn(fmt.Sprintf("Skipping %s: docker env files cannot hold multi-line values", k))
+				continue
+			}
+			fmt.Printf("%s=%s\n", strings.ToUpper(k), entries[k])
+		}
+	case "k8s-secret":
+		return printK8sSecret(entries)
+	default:
+		return fmt.Errorf("unsupported format %q (supported: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+	return nil
+}
+
+// awsCredentials reads the S3 credentials of the current environment.
+func awsCredentials() (id, secret, token, expiration string, err error) {
+	id = viper.GetString("aws_access_key_id")
+	secret = viper.GetString("aws_secret_access_key")
+	if id == "" || secret == "" {
+		return "", "", "", "", errors.New("no S3 credentials in the current environment")
+	}
+	return id, secret, viper.GetString("aws_session_token"), viper.GetString("aws_credentials_expiration"), nil
+}
+
+// printAwsProcess writes the JSON expected from an AWS credential_process.
+func printAwsProcess() error {
+	id, secret, token, expiration, err := awsCredentials()
+	if err != nil {
+		return err
+	}
+	out := struct {
+		Version         int    `json:"Version"`
+		AccessKeyId     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken,omitempty"`
+		Expiration      string `json:"Expiration,omitempty"`
+	}{1, id, secret, token, expiration}
+
+	b, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// printAwsProfile writes a ~/.aws/credentials section named after the
+// environment.
+func printAwsProfile() error {
+	id, secret, token, _, err := awsCredentials()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[%s]\n", viper.GetString(keys.CurrentEnvironment))
+	fmt.Printf("aws_access_key_id = %s\n", id)
+	fmt.Printf("aws_secret_access_key = %s\n", secret)
+	if token != "" {
+		fmt.Printf("aws_session_token = %s\n", token)
+	}
+	return nil
+}
+
+var k8sNameInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// printK8sSecret writes an Opaque Secret manifest holding the credentials.
+func printK8sSecret(entries map[string]string) error {
+	name := k8sNameInvalid.ReplaceAllString(strings.ToLower(viper.GetString(keys.CurrentEnvironment)), "-")
+	name = strings.Trim(name+"-credentials", "-")
+
+	data := make(map[string]string, len(entries))
+	for k, v := range entries {
+		data[strings.ToUpper(k)] = v
+	}
+	secret := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]string{"name": name},
+		"type":       "Opaque",
+		"stringData": data,
+	}
+	b, err := yaml.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// shellQuote quotes a value for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// dotenvQuote quotes a value for .env files when it needs it.
+func dotenvQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\r\n\"'#$\\=`") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// WriteAwsProfileHandler upserts the named profile in the AWS shared
+// credentials and config files (AWS_SHARED_CREDENTIALS_FILE and
+// AWS_CONFIG_FILE are honoured). With process the profile runs dhcli as
+// credential_process instead of holding a copy of the keys, so the tools
+// always get current credentials.
+func WriteAwsProfileHandler(profile string, process bool) error {
+	utils.CheckUpdateEnvironment()
+	if err := utils.CheckCredentials(); err != nil {
+		return err
+	}
+	if err := utils.EnsureS3Credentials(); err != nil {
+		return err
+	}
+	id, secret, token, _, err := awsCredentials()
+	if err != nil {
+		return err
+	}
+
+	credPath, confPath, err := awsSharedFiles()
+	if err != nil {
+		return err
+	}
+
+	// config: [profile <name>], except for the default profile
+	section := "profile " + profile
+	if profile == "default" {
+		section = profile
+	}
+	confValues := map[string]string{
+		"region":       viper.GetString("aws_region"),
+		"endpoint_url": viper.GetString("aws_endpoint_url"),
+	}
+	var credValues map[string]string
+	if process {
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		// static keys would take precedence: the credentials section is dropped
+		confValues["credential_process"] = fmt.Sprintf("%s credentials --format aws-process -e %s",
+			quoteProcessArg(exe), quoteProcessArg(viper.GetString(keys.CurrentEnvironment)))
+	} else {
+		credValues = map[string]string{
+			"aws_access_key_id":     id,
+			"aws_secret_access_key": secret,
+			"aws_session_token":     token,
+		}
+		confValues["credential_process"] = ""
+	}
+
+	if err := upsertAwsFile(credPath, profile, credValues); err != nil {
+		return err
+	}
+	if err := upsertAwsFile(confPath, section, confValues); err != nil {
+		return err
+	}
+
+	utils.GetGlobalLogger().Success(fmt.Sprintf("AWS profile '%s' written to %s and %s", profile, credPath, confPath))
+	return nil
+}
+
+func awsSharedFiles() (string, string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	credPath := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credPath == "" {
+		credPath = filepath.Join(home, ".aws", "credentials")
+	}
+	confPath := os.Getenv("AWS_CONFIG_FILE")
+	if confPath == "" {
+		confPath = filepath.Join(home, ".aws", "config")
+	}
+	return credPath, confPath, nil
+}
+
+// upsertAwsFile sets the given keys of a section, removing the ones with an
+// empty value; a nil map removes the whole section. Other sections and keys
+// are preserved.
+func upsertAwsFile(path string, section string, values map[string]string) error {
+	cfg, err := ini.LoadSources(ini.LoadOptions{Loose: true, IgnoreInlineComment: true}, path)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	if values == nil {
+		cfg.DeleteSection(section)
+	} else {
+		sec := cfg.Section(section)
+		names := make([]string, 0, len(values))
+		for k := range values {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			if v := values[k]; v == "" {
+				sec.DeleteKey(k)
+			} else {
+				sec.Key(k).SetValue(v)
+			}
+		}
+	}
+
+	// the AWS files have no default section; dhcli writes its header otherwise
+	if len(cfg.Section(ini.DefaultSection).Keys()) == 0 {
+		cfg.DeleteSection(ini.DefaultSection)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := cfg.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// quoteProcessArg quotes a credential_process argument containing spaces.
+func quoteProcessArg(s string) string {
+	if strings.ContainsAny(s, " \t\"") {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return s
+}
@@ -17,8 +17,9 @@ import (
 
 func ConfigHandler(output string, provider string) error {
 	utils.CheckUpdateEnvironment()
-	entries := getConfigEntriesByProvider(provider, output)
-	return printEntries(entries, utils.TranslateFormat(output))
+	format := utils.TranslateFormat(output)
+	entries := getConfigEntriesByProvider(provider, format)
+	return printEntries(entries, format)
 }
 
 func CredentialsHandler(output string, provider string) error {
@@ -26,8 +27,30 @@ func CredentialsHandler(output string, provider string) error {
 	if err := utils.CheckCredentials(); err != nil {
 		return err
 	}
-	entries := getCredentialEntriesByProvider(provider, output)
-	return printEntries(entries, utils.TranslateFormat(output))
+	format := utils.TranslateFormat(output)
+	entries := getCredentialEntriesByProvider(provider, format)
+	return printEntries(entries, format)
+}
+
+// SecretStoreHandler moves every stored credential to the given backend
+// (plaintext, keyring or file) and makes it the default for new ones.
+func SecretStoreHandler(store string) error {
+	moved, err := utils.MigrateSecretStore(store)
+	if err != nil {
+		return err
+	}
+	name, _ := utils.SecretStoreName()
+	var msg string
+	switch name {
+	case "plaintext":
+		msg = fmt.Sprintf("Credentials are now stored in the INI file (%d values moved)", moved)
+	case "file":
+		msg = fmt.Sprintf("Credentials are now kept in %s (%d values moved)", utils.SecretFilePath(), moved)
+	default:
+		msg = fmt.Sprintf("Credentials are now kept in the %s store (%d values moved)", name, moved)
+	}
+	utils.GetGlobalLogger().Success(msg)
+	return nil
 }
 
 func printEntries(entries map[string]string, format string) error {
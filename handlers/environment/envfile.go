@@ -0,0 +1,344 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package environment
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dhcli/handlers/secrets"
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+
+	"gopkg.in/ini.v1"
+	"sigs.k8s.io/yaml"
+)
+
+// EnvDefinition is the shareable form of an environment: its INI section
+// without credentials.
+type EnvDefinition struct {
+	Name   string            `json:"name"`
+	Values map[string]string `json:"values"`
+}
+
+// EnvExportHandler prints the definition of an environment as YAML. The
+// environment defaults to the current one.
+func EnvExportHandler(env string) error {
+	cfg := utils.LoadIni(false)
+	env, err := existingEnv(cfg, env)
+	if err != nil {
+		return err
+	}
+
+	def := EnvDefinition{Name: env, Values: map[string]string{}}
+	for _, k := range shareableKeys(cfg.Section(env)) {
+		def.Values[k.Name()] = k.Value()
+	}
+	b, err := yaml.Marshal(def)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// EnvImportHandler creates an environment from a definition written by
+// EnvExportHandler; path "-" reads it from stdin. With refresh the well-known
+// configuration is fetched again from the endpoint and replaces the exported
+// values it defines, while hand-tuned keys are kept.
+func EnvImportHandler(path string, as string, refresh bool, force bool) error {
+	var (
+		b   []byte
+		err error
+	)
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	var def EnvDefinition
+	if err := yaml.UnmarshalStrict(b, &def); err != nil {
+		return fmt.Errorf("invalid environment definition %s: %w", path, err)
+	}
+
+	env := def.Name
+	if as != "" {
+		env = as
+	}
+	if err := utils.CheckEnvName(env); err != nil {
+		return err
+	}
+
+	values := map[string]string{}
+	for k, v := range def.Values {
+		if k == keys.CurrentIdentity {
+			continue
+		}
+		if isCredential(k, v) {
+			utils.GetGlobalLogger().Warn(fmt.Sprintf("Skipping credential %s", k))
+			continue
+		}
+		values[k] = v
+	}
+
+	if refresh {
+		endpoint := values[keys.DhCoreEndpoint]
+		if endpoint == "" {
+			return fmt.Errorf("cannot refresh: %s is not defined", keys.DhCoreEndpoint)
+		}
+		transport, err := utils.ReadTransportSettings(func(key string) string { return values[key] })
+		if err != nil {
+			return err
+		}
+		if err := utils.ConfigureTransport(transport); err != nil {
+			return err
+		}
+		fetched, err := fetchWellKnown(endpoint)
+		if err != nil {
+			return err
+		}
+		for k, v := range fetched {
+			values[k] = v
+		}
+		values[keys.UpdatedEnvKey] = time.Now().UTC().Format(time.RFC3339)
+		values[keys.IniSource] = "well-known"
+	}
+
+	if err := checkApiLevel(values[keys.ApiLevelKey]); err != nil {
+		return err
+	}
+
+	unlock, err := utils.LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg := utils.LoadIni(true)
+	if err := replaceableEnv(cfg, env, force); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	section := cfg.Section(env)
+	for _, k := range names {
+		section.Key(k).SetValue(values[k])
+	}
+
+	defaultSection := cfg.Section("DEFAULT")
+	if !defaultSection.HasKey(keys.CurrentEnvironment) {
+		defaultSection.Key(keys.CurrentEnvironment).SetValue(env)
+	}
+
+	utils.SaveIni(cfg)
+	utils.GetGlobalLogger().Success(fmt.Sprintf("'%s' imported.", env))
+	return nil
+}
+
+// EnvCloneHandler copies an environment under a new name, without its
+// credentials.
+func EnvCloneHandler(src string, dst string, force bool) error {
+	if err := utils.CheckEnvName(dst); err != nil {
+		return err
+	}
+	unlock, err := utils.LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg := utils.LoadIni(false)
+	if _, err := existingEnv(cfg, src); err != nil {
+		return err
+	}
+	if src == dst {
+		return fmt.Errorf("source and destination are both '%s'", src)
+	}
+	if err := replaceableEnv(cfg, dst, force); err != nil {
+		return err
+	}
+
+	from, to := cfg.Section(src), cfg.Section(dst)
+	to.Comment = from.Comment
+	for _, k := range shareableKeys(from) {
+		to.Key(k.Name()).SetValue(k.Value())
+		to.Key(k.Name()).Comment = k.Comment
+	}
+
+	utils.SaveIni(cfg)
+	utils.GetGlobalLogger().Success(fmt.Sprintf("'%s' cloned to '%s'.", src, dst))
+	return nil
+}
+
+// EnvRenameHandler renames an environment, keeping its credentials.
+func EnvRenameHandler(src string, dst string) error {
+	if err := utils.CheckEnvName(dst); err != nil {
+		return err
+	}
+	unlock, err := utils.LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg := utils.LoadIni(false)
+	if _, err := existingEnv(cfg, src); err != nil {
+		return err
+	}
+	if cfg.HasSection(dst) {
+		return fmt.Errorf("environment '%s' already exists", dst)
+	}
+
+	if err := renameSection(cfg, src, dst); err != nil {
+		return err
+	}
+	for _, identity := range utils.EnvIdentities(cfg, src) {
+		if err := renameSection(cfg, utils.IdentitySection(src, identity), utils.IdentitySection(dst, identity)); err != nil {
+			return err
+		}
+	}
+
+	defaultSection := cfg.Section("DEFAULT")
+	if defaultSection.HasKey(keys.CurrentEnvironment) && defaultSection.Key(keys.CurrentEnvironment).String() == src {
+		defaultSection.Key(keys.CurrentEnvironment).SetValue(dst)
+	}
+
+	utils.SaveIni(cfg)
+	utils.GetGlobalLogger().Success(fmt.Sprintf("'%s' renamed to '%s'.", src, dst))
+	return nil
+}
+
+// renameSection moves the keys of a section, and the secrets they reference,
+// to a new section.
+func renameSection(cfg *ini.File, src string, dst string) error {
+	from := cfg.Section(src)
+	to, err := cfg.NewSection(dst)
+	if err != nil {
+		return err
+	}
+	to.Comment = from.Comment
+	for _, k := range from.Keys() {
+		to.Key(k.Name()).SetValue(k.Value())
+		to.Key(k.Name()).Comment = k.Comment
+	}
+	// secrets are stored per section
+	if err := utils.MoveSectionSecrets(to, dst); err != nil {
+		return err
+	}
+	cfg.DeleteSection(src)
+	return nil
+}
+
+// existingEnv returns env, or the current environment when it is empty, and
+// checks that its section exists.
+func existingEnv(cfg *ini.File, env string) (string, error) {
+	if env == "" {
+		def := cfg.Section("DEFAULT")
+		if def.HasKey(keys.CurrentEnvironment) {
+			env = def.Key(keys.CurrentEnvironment).String()
+		}
+		if env == "" {
+			return "", fmt.Errorf("no environment given and no current environment set")
+		}
+	}
+	if _, _, ok := utils.SplitIdentitySection(env); ok || env == "DEFAULT" || !cfg.HasSection(env) {
+		return "", fmt.Errorf("environment '%s' does not exist", env)
+	}
+	return env, nil
+}
+
+// replaceableEnv checks that env may be written: it must not exist unless
+// force is set, in which case its content and secrets are removed.
+func replaceableEnv(cfg *ini.File, env string, force bool) error {
+	if !cfg.HasSection(env) {
+		return nil
+	}
+	if !force {
+		return fmt.Errorf("environment '%s' already exists. Use --force to override", env)
+	}
+	section := cfg.Section(env)
+	utils.DeleteSectionSecrets(section)
+	for _, k := range section.Keys() {
+		section.DeleteKey(k.Name())
+	}
+	return nil
+}
+
+// shareableKeys returns the keys of a section that are not credentials nor
+// local choices such as the identity in use.
+func shareableKeys(sec *ini.Section) []*ini.Key {
+	listed := map[string]bool{}
+	if sec.HasKey(keys.CredentialsList) {
+		for _, k := range utils.SplitCSV(sec.Key(keys.CredentialsList).String()) {
+			listed[k] = true
+		}
+	}
+	var out []*ini.Key
+	for _, k := range sec.Keys() {
+		if listed[k.Name()] || k.Name() == keys.CurrentIdentity || isCredential(k.Name(), k.Value()) {
+			continue
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// isCredential reports whether a key must never leave the machine or be
+// taken from a shared definition.
+func isCredential(key string, value string) bool {
+	if key == keys.CredentialsList || key == keys.DhCoreGrantType || keys.CredentialKeys[key] {
+		return true
+	}
+	_, _, ok := secrets.ParseRef(value)
+	return ok
+}
+
+func checkApiLevel(level string) error {
+	apiLevel, err := strconv.Atoi(level)
+	if err != nil {
+		return fmt.Errorf("API level not valid or missing")
+	}
+	if apiLevel < keys.MinApiLevel {
+		return fmt.Errorf("API level %v < minimum required %v", apiLevel, keys.MinApiLevel)
+	}
+	return nil
+}
+
+// fetchWellKnown reads the core and OpenID configurations of an endpoint as
+// INI values, the same way RegisterHandler does.
+func fetchWellKnown(endpoint string) (map[string]string, error) {
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	config, err := utils.FetchConfig(endpoint + ".well-known/configuration")
+	if err != nil {
+		return nil, fmt.Errorf("fetching configuration failed: %w", err)
+	}
+	openIdConfig, err := utils.FetchConfig(endpoint + ".well-known/openid-configuration")
+	if err != nil {
+		return nil, fmt.Errorf("fetching OpenID configuration failed: %w", err)
+	}
+
+	values := make(map[string]string, len(config)+len(openIdConfig))
+	for k, v := range config {
+		values[k] = utils.ReflectValue(v)
+	}
+	for k, v := range openIdConfig {
+		values["oauth2_"+k] = utils.ReflectValue(v)
+	}
+	return values, nil
+}
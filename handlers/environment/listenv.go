@@ -6,8 +6,11 @@ package environment
 
 import (
 	"log"
+	"strings"
 
 	"dhcli/handlers/utils"
+
+	"gopkg.in/ini.v1"
 )
 
 func ListEnvHandler() {
@@ -15,16 +18,25 @@ func ListEnvHandler() {
 
 	currentEnv := cfg.Section("DEFAULT").Key("current_environment").String()
 	if currentEnv != "" {
-		log.Printf("Current environment: %s\n", currentEnv)
+		identity := utils.DefaultIdentity
+		if cfg.HasSection(currentEnv) {
+			identity = utils.RecordedIdentity(cfg.Section(currentEnv))
+		}
+		if identity != utils.DefaultIdentity {
+			log.Printf("Current environment: %s (identity: %s)\n", currentEnv, identity)
+		} else {
+			log.Printf("Current environment: %s\n", currentEnv)
+		}
 	}
 
 	sections := cfg.SectionStrings()
 	sectionsString := ""
 
 	for _, name := range sections {
-		if name != "DEFAULT" {
-			sectionsString += name + ", "
+		if _, _, ok := utils.SplitIdentitySection(name); ok || name == "DEFAULT" {
+			continue
 		}
+		sectionsString += name + identitiesSuffix(cfg, name) + ", "
 	}
 
 	if sectionsString == "" {
@@ -35,3 +47,21 @@ func ListEnvHandler() {
 
 	log.Printf("Available environments: %s\n", sectionsString)
 }
+
+// identitiesSuffix lists the identities of an environment having more than
+// the default one, the one in use marked with '*'.
+func identitiesSuffix(cfg *ini.File, env string) string {
+	identities := utils.EnvIdentities(cfg, env)
+	if len(identities) == 0 {
+		return ""
+	}
+	active := utils.RecordedIdentity(cfg.Section(env))
+	names := make([]string, 0, len(identities)+1)
+	for _, identity := range append([]string{utils.DefaultIdentity}, identities...) {
+		if identity == active {
+			identity += "*"
+		}
+		names = append(names, identity)
+	}
+	return " (identities: " + strings.Join(names, ", ") + ")"
+}
@@ -14,9 +14,14 @@ import (
 
 	"dhcli/handlers/utils"
 	"dhcli/keys"
+
+	"gopkg.in/ini.v1"
 )
 
-func RegisterHandler(env string, endpoint string, force bool) error {
+// RegisterHandler writes the configuration of a core instance as an
+// environment. The transport settings given replace the ones already stored
+// for the environment, which are otherwise kept.
+func RegisterHandler(env string, endpoint string, force bool, transport utils.TransportSettings) error {
 	if endpoint == "" {
 		return fmt.Errorf("endpoint is required")
 	}
@@ -24,8 +29,31 @@ func RegisterHandler(env string, endpoint string, force bool) error {
 		endpoint += "/"
 	}
 
+	unlock, err := utils.LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	cfg := utils.LoadIni(true)
 
+	// 0. Configure TLS and proxy before contacting the endpoint, starting
+	// from the settings stored for the environment or the endpoint
+	transport, err = transport.AbsPaths()
+	if err != nil {
+		return err
+	}
+	if previous := previousSection(cfg, env, endpoint); previous != nil {
+		existing, err := utils.SectionTransportSettings(previous)
+		if err != nil {
+			return err
+		}
+		transport = existing.Merge(transport)
+	}
+	if err := utils.ConfigureTransport(transport); err != nil {
+		return err
+	}
+
 	// 1. Fetch core config
 	config, err := utils.FetchConfig(endpoint + ".well-known/configuration")
 	if err != nil {
@@ -38,6 +66,9 @@ func RegisterHandler(env string, endpoint string, force bool) error {
 			return fmt.Errorf("environment not specified and not defined in core configuration")
 		}
 	}
+	if err := utils.CheckEnvName(env); err != nil {
+		return err
+	}
 
 	// 2. Check for endpoint conflict before clearing section
 	if cfg.HasSection(env) {
@@ -99,13 +130,24 @@ func RegisterHandler(env string, endpoint string, force bool) error {
 		section.Key(targetKey).SetValue(valStr)
 	}
 
-	// 7. Add timestamp
+	// 7. Keep transport settings
+	transportValues := transport.Values()
+	transportKeys := make([]string, 0, len(transportValues))
+	for k := range transportValues {
+		transportKeys = append(transportKeys, k)
+	}
+	sort.Strings(transportKeys)
+	for _, k := range transportKeys {
+		section.Key(k).SetValue(transportValues[k])
+	}
+
+	// 8. Add timestamp
 	section.NewKey(keys.UpdatedEnvKey, time.Now().UTC().Format(time.RFC3339))
 
-	// 8. Add ini_source
+	// 9. Add ini_source
 	section.NewKey(keys.IniSource, "well-known")
 
-	// 9. Set default as default environment if not already set
+	// 10. Set default as default environment if not already set
 	defaultSection := cfg.Section("DEFAULT")
 	if defaultSection.HasKey(keys.CurrentEnvironment) {
 		defaultSection.DeleteKey(keys.CurrentEnvironment)
@@ -117,3 +159,21 @@ func RegisterHandler(env string, endpoint string, force bool) error {
 	log.Printf("'%v' registered.\n", env)
 	return nil
 }
+
+// previousSection returns the section of env or, when env is not given yet,
+// the first one registered for the same endpoint.
+func previousSection(cfg *ini.File, env string, endpoint string) *ini.Section {
+	if env != "" && env != "null" {
+		if cfg.HasSection(env) {
+			return cfg.Section(env)
+		}
+		return nil
+	}
+	for _, sec := range cfg.Sections() {
+		if sec.HasKey(keys.DhCoreEndpoint) &&
+			strings.TrimSuffix(sec.Key(keys.DhCoreEndpoint).String(), "/") == strings.TrimSuffix(endpoint, "/") {
+			return sec
+		}
+	}
+	return nil
+}
@@ -9,19 +9,43 @@ import (
 	"os"
 
 	"dhcli/handlers/utils"
+	"dhcli/keys"
 )
 
+// RemoveHandler removes an environment together with its identities, or a
+// single identity when env is given as <environment>@<identity>.
 func RemoveHandler(env string) {
 	sectionName := env
 
+	unlock, err := utils.LockIni()
+	if err != nil {
+		log.Fatalf("Cannot lock the configuration: %v", err)
+	}
+	defer unlock()
+
 	cfg := utils.LoadIni(false)
 	if !cfg.HasSection(sectionName) {
 		log.Printf("Specified environment does not exist.\n")
 		os.Exit(0)
 	}
 
+	utils.DeleteSectionSecrets(cfg.Section(sectionName))
 	cfg.DeleteSection(sectionName)
 
+	if parent, identity, ok := utils.SplitIdentitySection(sectionName); ok {
+		if cfg.HasSection(parent) && utils.RecordedIdentity(cfg.Section(parent)) == identity {
+			cfg.Section(parent).DeleteKey(keys.CurrentIdentity)
+		}
+		utils.SaveIni(cfg)
+		log.Printf("Identity '%v' of '%v' has been removed.\n", identity, parent)
+		return
+	}
+	for _, identity := range utils.EnvIdentities(cfg, sectionName) {
+		name := utils.IdentitySection(sectionName, identity)
+		utils.DeleteSectionSecrets(cfg.Section(name))
+		cfg.DeleteSection(name)
+	}
+
 	defaultSection := cfg.Section("DEFAULT")
 	if defaultSection.Key("current_environment").String() == sectionName {
 		defaultSection.DeleteKey("current_environment")
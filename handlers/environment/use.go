@@ -9,12 +9,21 @@ import (
 	"os"
 
 	"dhcli/handlers/utils"
+	"dhcli/keys"
 )
 
-func UseHandler(env string) {
+// UseHandler makes env the current environment. A non-empty identity becomes
+// the one used in env from now on.
+func UseHandler(env string, identity string) {
 	environmentName := env
+	unlock, err := utils.LockIni()
+	if err != nil {
+		log.Fatalf("Cannot lock the configuration: %v", err)
+	}
+	defer unlock()
+
 	cfg := utils.LoadIni(false)
-	if !cfg.HasSection(environmentName) {
+	if _, _, ok := utils.SplitIdentitySection(environmentName); ok || !cfg.HasSection(environmentName) {
 		log.Printf("Specified environment does not exist.\n")
 		os.Exit(1)
 	}
@@ -22,6 +31,25 @@ func UseHandler(env string) {
 	defaultSection := cfg.Section("DEFAULT")
 	defaultSection.Key("current_environment").SetValue(environmentName)
 
+	if identity != "" {
+		if err := utils.CheckIdentityName(identity); err != nil {
+			log.Fatalf("%v", err)
+		}
+		section := cfg.Section(environmentName)
+		if identity == utils.DefaultIdentity {
+			section.DeleteKey(keys.CurrentIdentity)
+		} else {
+			section.Key(keys.CurrentIdentity).SetValue(identity)
+			if !cfg.HasSection(utils.IdentitySection(environmentName, identity)) {
+				log.Printf("Identity '%v' has no credentials yet: run 'dhcli login -e %v --as %v'.\n", identity, environmentName, identity)
+			}
+		}
+	}
+
 	utils.SaveIni(cfg)
-	log.Printf("Switched default to '%v'.\n", environmentName)
+	if identity != "" {
+		log.Printf("Switched default to '%v' as '%v'.\n", environmentName, identity)
+	} else {
+		log.Printf("Switched default to '%v'.\n", environmentName)
+	}
 }
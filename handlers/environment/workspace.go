@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dhcli/handlers/utils"
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// WorkspaceInitHandler creates a .dhcli.yaml in the working directory. The
+// environment defaults to the current one.
+func WorkspaceInitHandler(env string, project string, output string, force bool) error {
+	switch strings.ToLower(output) {
+	case "", "short", "json", "yaml", "yml":
+	default:
+		return fmt.Errorf("unsupported output format %q (short, json, yaml)", output)
+	}
+	if env == "" {
+		env = viper.GetString(keys.CurrentEnvironment)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	ws := &utils.Workspace{
+		Environment: env,
+		Project:     project,
+		Output:      output,
+		Path:        filepath.Join(cwd, keys.WorkspaceFile),
+	}
+	if err := utils.WriteWorkspace(ws, force); err != nil {
+		return err
+	}
+	utils.GetGlobalLogger().Success(fmt.Sprintf("Workspace written to %s", ws.Path))
+	return nil
+}
+
+// WorkspaceShowHandler prints the workspace file in effect for the working
+// directory.
+func WorkspaceShowHandler(output string) error {
+	ws := utils.CurrentWorkspace()
+	if ws == nil {
+		fmt.Printf("No %s found in the working directory or its parents.\n", keys.WorkspaceFile)
+		return nil
+	}
+
+	view := struct {
+		Path string `json:"path"`
+		utils.Workspace
+	}{ws.Path, *ws}
+
+	switch utils.TranslateFormat(output) {
+	case "json":
+		b, err := json.MarshalIndent(view, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(view)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		fmt.Printf("File:         %s\n", ws.Path)
+		fmt.Printf("Environment:  %s\n", ws.Environment)
+		fmt.Printf("Project:      %s\n", ws.Project)
+		fmt.Printf("Output:       %s\n", ws.Output)
+		if len(ws.Run) > 0 {
+			b, err := yaml.Marshal(ws.Run)
+			if err != nil {
+				return err
+			}
+			fmt.Println("Run defaults:")
+			for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+				fmt.Println("  " + line)
+			}
+		}
+	}
+	return nil
+}
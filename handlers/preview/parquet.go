@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// Parquet files are decoded through ranged reads: the footer gives the schema
+// and the row count, then only the pages holding the previewed rows are
+// fetched.
+
+// parquetReadBuffer is the size of the ranged reads, so that most pages are
+// fetched in a single request.
+const parquetReadBuffer = 1 << 20
+
+func previewParquet(r RangeReader, maxRows int) (*Result, error) {
+	size := r.Size()
+	if size < 0 {
+		return nil, errors.New("the size of Parquet files must be known")
+	}
+	f, err := parquet.OpenFile(rangeReaderAt{r}, size,
+		parquet.SkipPageIndex(true),
+		parquet.SkipBloomFilters(true),
+		parquet.ReadBufferSize(parquetReadBuffer))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Parquet file: %w", err)
+	}
+	elems := f.Metadata().Schema
+	if len(elems) == 0 {
+		return nil, errors.New("parquet footer has no schema")
+	}
+
+	res := &Result{Format: FormatParquet, TotalRows: f.NumRows()}
+	var paths [][]string
+	w := &schemaWalker{elems: elems, pos: 1}
+	for i := 0; i < int(elems[0].NumChildren) && w.pos < len(w.elems); i++ {
+		fields, fieldPaths := w.walk(nil)
+		res.Schema = append(res.Schema, fields...)
+		paths = append(paths, fieldPaths...)
+	}
+	for _, f := range res.Schema {
+		res.Columns = append(res.Columns, f.Name)
+	}
+
+	if res.Rows, err = readParquetRows(f, paths, maxRows); err != nil {
+		return nil, fmt.Errorf("failed to read Parquet rows: %w", err)
+	}
+	res.Truncated = int64(len(res.Rows)) < res.TotalRows
+	return res, nil
+}
+
+// readParquetRows returns the values of the columns at paths for up to
+// maxRows rows, reading the row groups in order.
+func readParquetRows(f *parquet.File, paths [][]string, maxRows int) ([][]interface{}, error) {
+	var out [][]interface{}
+	for _, rg := range f.RowGroups() {
+		if len(out) >= maxRows {
+			break
+		}
+		rows := rg.Rows()
+		buf := make([]parquet.Row, min(maxRows-len(out), 128))
+		for len(out) < maxRows {
+			n, err := rows.ReadRows(buf[:min(len(buf), maxRows-len(out))])
+			for _, row := range buf[:n] {
+				// the buffers of row are reused by the next read
+				values := map[string]interface{}{}
+				if err := f.Schema().Reconstruct(&values, row); err != nil {
+					rows.Close()
+					return out, err
+				}
+				cells := make([]interface{}, len(paths))
+				for i, p := range paths {
+					cells[i] = parquetCell(lookupPath(values, p))
+				}
+				out = append(out, cells)
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				rows.Close()
+				return out, err
+			}
+		}
+		rows.Close()
+	}
+	return out, nil
+}
+
+func lookupPath(values map[string]interface{}, path []string) interface{} {
+	var v interface{} = values
+	for _, k := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+// parquetCell copies binary values, which may point into the read buffers,
+// as strings.
+func parquetCell(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// schemaWalker flattens the depth-first schema list into leaf columns.
+type schemaWalker struct {
+	elems []format.SchemaElement
+	pos   int
+}
+
+// walk returns the columns of the next element with their path in the rows.
+func (w *schemaWalker) walk(parent []string) ([]Field, [][]string) {
+	e := w.elems[w.pos]
+	w.pos++
+	path := append(append([]string(nil), parent...), e.Name)
+	name := strings.Join(path, ".")
+	if e.NumChildren == 0 {
+		return []Field{{Name: name, Type: parquetTypeName(e)}}, [][]string{path}
+	}
+
+	// LIST and MAP groups are reported as one column.
+	lt, ct := e.LogicalType, e.ConvertedType
+	collapsed := ""
+	switch {
+	case (lt != nil && lt.List != nil) || (ct != nil && *ct == deprecated.List):
+		collapsed = "list"
+	case (lt != nil && lt.Map != nil) || (ct != nil && (*ct == deprecated.Map || *ct == deprecated.MapKeyValue)):
+		collapsed = "map"
+	}
+
+	var fields []Field
+	var paths [][]string
+	for i := 0; i < int(e.NumChildren) && w.pos < len(w.elems); i++ {
+		f, p := w.walk(path)
+		fields = append(fields, f...)
+		paths = append(paths, p...)
+	}
+	if collapsed != "" {
+		return []Field{{Name: name, Type: collapsed}}, [][]string{path}
+	}
+	return fields, paths
+}
+
+// parquetTypeName renders the logical type when present, else the physical
+// one.
+func parquetTypeName(e format.SchemaElement) string {
+	if lt := e.LogicalType; lt != nil {
+		switch {
+		case lt.UTF8 != nil, lt.Enum != nil, lt.Json != nil, lt.UUID != nil:
+			return "string"
+		case lt.Decimal != nil:
+			return "decimal"
+		case lt.Date != nil:
+			return "date"
+		case lt.Time != nil:
+			return "time"
+		case lt.Timestamp != nil:
+			return "timestamp"
+		}
+	}
+	if ct := e.ConvertedType; ct != nil {
+		switch *ct {
+		case deprecated.UTF8, deprecated.Enum, deprecated.Json:
+			return "string"
+		case deprecated.Decimal:
+			return "decimal"
+		case deprecated.Date:
+			return "date"
+		case deprecated.TimeMillis, deprecated.TimeMicros:
+			return "time"
+		case deprecated.TimestampMillis, deprecated.TimestampMicros:
+			return "timestamp"
+		}
+	}
+	if e.Type == nil {
+		return "unknown"
+	}
+	switch *e.Type {
+	case format.Boolean:
+		return "boolean"
+	case format.Int32:
+		return "int32"
+	case format.Int64:
+		return "int64"
+	case format.Int96:
+		return "timestamp" // legacy timestamps
+	case format.Float:
+		return "float"
+	case format.Double:
+		return "double"
+	case format.ByteArray, format.FixedLenByteArray:
+		return "binary"
+	}
+	return "unknown"
+}
+
+// rangeReaderAt adapts a RangeReader to the io.ReaderAt the decoder reads.
+type rangeReaderAt struct {
+	r RangeReader
+}
+
+func (a rangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	b, err := a.r.ReadRange(off, int64(len(p)))
+	n := copy(p, b)
+	if err != nil {
+		return n, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
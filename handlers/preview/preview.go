@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package preview inspects tabular files from a few ranged reads: the head of
+// CSV and JSON lines files, the footer and first rows of Parquet files.
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Supported formats.
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// headBytes is how much of a text file is fetched to build a preview.
+const headBytes = 256 * 1024
+
+// RangeReader reads byte ranges of a remote object.
+type RangeReader interface {
+	// Size returns the object size in bytes.
+	Size() int64
+	// ReadRange returns length bytes starting at offset (fewer at EOF).
+	ReadRange(offset, length int64) ([]byte, error)
+}
+
+// Field is a column of a schema.
+type Field struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// Result is the preview of a single file.
+type Result struct {
+	Format    string          `json:"format"               yaml:"format"`
+	Schema    []Field         `json:"schema"               yaml:"schema"`
+	Columns   []string        `json:"columns,omitempty"    yaml:"columns,omitempty"`
+	Rows      [][]interface{} `json:"rows,omitempty"       yaml:"rows,omitempty"`
+	TotalRows int64           `json:"total_rows,omitempty" yaml:"total_rows,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"  yaml:"truncated,omitempty"`
+}
+
+// DetectFormat guesses the format from the file name.
+func DetectFormat(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv", ".tsv", ".txt":
+		return FormatCSV
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL
+	case ".parquet", ".pq":
+		return FormatParquet
+	}
+	return ""
+}
+
+// Preview reads the schema and up to maxRows rows of the file.
+func Preview(r RangeReader, format string, maxRows int) (*Result, error) {
+	switch format {
+	case FormatParquet:
+		return previewParquet(r, maxRows)
+	case FormatCSV, FormatJSONL:
+		size := r.Size()
+		n := int64(headBytes)
+		if size >= 0 && size < n {
+			n = size
+		}
+		head, err := r.ReadRange(0, n)
+		if err != nil {
+			return nil, err
+		}
+		// The last line of a partial read is most likely cut.
+		partial := size < 0 || int64(len(head)) < size
+		if format == FormatCSV {
+			return previewCSV(head, partial, maxRows)
+		}
+		return previewJSONL(head, partial, maxRows)
+	case "":
+		return nil, errors.New("unknown file format")
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Difference is a mismatch between a stored and an actual schema.
+type Difference struct {
+	Field  string `json:"field"            yaml:"field"`
+	Issue  string `json:"issue"            yaml:"issue"`
+	Stored string `json:"stored,omitempty" yaml:"stored,omitempty"`
+	Actual string `json:"actual,omitempty" yaml:"actual,omitempty"`
+}
+
+// Schema difference issues.
+const (
+	IssueMissing = "missing in file"
+	IssueExtra   = "not in stored schema"
+	IssueType    = "type mismatch"
+)
+
+// CompareSchemas lists the differences between the stored schema and the one
+// read from the file. Types are compared after normalization, so "int64" and
+// "integer" match.
+func CompareSchemas(stored, actual []Field) []Difference {
+	var diffs []Difference
+	act := map[string]Field{}
+	for _, f := range actual {
+		act[f.Name] = f
+	}
+	seen := map[string]bool{}
+	for _, s := range stored {
+		seen[s.Name] = true
+		a, ok := act[s.Name]
+		switch {
+		case !ok:
+			diffs = append(diffs, Difference{Field: s.Name, Issue: IssueMissing, Stored: s.Type})
+		case s.Type != "" && a.Type != "" && NormalizeType(s.Type) != NormalizeType(a.Type):
+			diffs = append(diffs, Difference{Field: s.Name, Issue: IssueType, Stored: s.Type, Actual: a.Type})
+		}
+	}
+	for _, a := range actual {
+		if !seen[a.Name] {
+			diffs = append(diffs, Difference{Field: a.Name, Issue: IssueExtra, Actual: a.Type})
+		}
+	}
+	return diffs
+}
+
+// NormalizeType maps the type names of the different sources (frictionless,
+// pandas, Parquet) onto a common vocabulary.
+func NormalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	switch {
+	case strings.HasPrefix(t, "int"), strings.HasPrefix(t, "uint"), t == "long", t == "short":
+		return "integer"
+	case strings.HasPrefix(t, "float"), t == "double", t == "number", strings.HasPrefix(t, "decimal"):
+		return "number"
+	case t == "bool", t == "boolean":
+		return "boolean"
+	case t == "str", t == "string", t == "object", t == "utf8":
+		return "string"
+	case strings.HasPrefix(t, "datetime"), strings.HasPrefix(t, "timestamp"):
+		return "datetime"
+	}
+	return t
+}
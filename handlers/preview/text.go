@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package preview
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// completeLines drops the trailing, possibly truncated, line of a partial read.
+func completeLines(head []byte, partial bool) []byte {
+	if !partial {
+		return head
+	}
+	if i := bytes.LastIndexByte(head, '\n'); i >= 0 {
+		return head[:i+1]
+	}
+	return head
+}
+
+// sniffDelimiter picks the most frequent candidate separator of the header.
+func sniffDelimiter(head []byte) rune {
+	line := head
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		line = head[:i]
+	}
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t', '|'} {
+		if n := bytes.Count(line, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func previewCSV(head []byte, partial bool, maxRows int) (*Result, error) {
+	data := completeLines(head, partial)
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = sniffDelimiter(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	var records [][]string
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+		records = append(records, rec)
+	}
+
+	// Types are inferred from every row fetched, not just the displayed ones.
+	res := &Result{Format: FormatCSV, Columns: header}
+	for i, name := range header {
+		var values []string
+		for _, rec := range records {
+			if i < len(rec) {
+				values = append(values, rec[i])
+			}
+		}
+		res.Schema = append(res.Schema, Field{Name: name, Type: inferType(values)})
+	}
+
+	for _, rec := range records {
+		if len(res.Rows) == maxRows {
+			res.Truncated = true
+			break
+		}
+		row := make([]interface{}, len(rec))
+		for i, v := range rec {
+			row[i] = v
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, nil
+}
+
+func previewJSONL(head []byte, partial bool, maxRows int) (*Result, error) {
+	data := completeLines(head, partial)
+
+	var objects []map[string]interface{}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal(line, &obj); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON line: %w", err)
+		}
+		objects = append(objects, obj)
+	}
+	if len(objects) == 0 {
+		return nil, errors.New("no JSON records found")
+	}
+
+	// Column order: first appearance across the sampled records.
+	var columns []string
+	types := map[string]string{}
+	for _, obj := range objects {
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			t := jsonType(obj[k])
+			prev, ok := types[k]
+			switch {
+			case !ok:
+				columns = append(columns, k)
+				types[k] = t
+			case t == "null" || prev == t:
+			case prev == "null":
+				types[k] = t
+			case prev == "integer" && t == "number", prev == "number" && t == "integer":
+				types[k] = "number"
+			default:
+				types[k] = "any"
+			}
+		}
+	}
+
+	res := &Result{Format: FormatJSONL, Columns: columns}
+	for _, c := range columns {
+		res.Schema = append(res.Schema, Field{Name: c, Type: types[c]})
+	}
+	for _, obj := range objects {
+		if len(res.Rows) == maxRows {
+			res.Truncated = true
+			break
+		}
+		row := make([]interface{}, len(columns))
+		for i, c := range columns {
+			row[i] = obj[c]
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, nil
+}
+
+func jsonType(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == float64(int64(x)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "any"
+}
+
+// inferType returns the narrowest type matching every non-empty value.
+func inferType(values []string) string {
+	candidates := []struct {
+		name string
+		ok   func(string) bool
+	}{
+		{"integer", func(s string) bool { _, err := strconv.ParseInt(s, 10, 64); return err == nil }},
+		{"number", func(s string) bool { _, err := strconv.ParseFloat(s, 64); return err == nil }},
+		{"boolean", func(s string) bool {
+			switch strings.ToLower(s) {
+			case "true", "false":
+				return true
+			}
+			return false
+		}},
+		{"date", func(s string) bool { _, err := time.Parse("2006-01-02", s); return err == nil }},
+		{"datetime", func(s string) bool {
+			for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+				if _, err := time.Parse(layout, s); err == nil {
+					return true
+				}
+			}
+			return false
+		}},
+	}
+
+	nonEmpty := 0
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			nonEmpty++
+		}
+	}
+	if nonEmpty == 0 {
+		return "string"
+	}
+
+	for _, c := range candidates {
+		all := true
+		for _, v := range values {
+			v = strings.TrimSpace(v)
+			if v != "" && !c.ok(v) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return c.name
+		}
+	}
+	return "string"
+}
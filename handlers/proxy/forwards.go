@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"dhcli/handlers/adapter"
+	"dhcli/handlers/utils"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ForwardManifest lists the services forwarded together by
+// `dhcli port-forward --manifest`.
+type ForwardManifest struct {
+	// Project is the default project of the forwards.
+	Project  string        `json:"project,omitempty"`
+	Forwards []ForwardSpec `json:"forwards"`
+}
+
+// ForwardSpec selects the run of one forward, by ID, function or run name,
+// and its local port (0 for a random one).
+type ForwardSpec struct {
+	Project  string `json:"project,omitempty"`
+	Run      string `json:"run,omitempty"`
+	Function string `json:"function,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Port     int    `json:"port,omitempty"`
+}
+
+// String returns the spec in the --forward syntax.
+func (s ForwardSpec) String() string {
+	var spec string
+	switch {
+	case s.Function != "":
+		spec = "fn=" + s.Function
+	case s.Name != "":
+		spec = "name=" + s.Name
+	default:
+		spec = "run=" + s.Run
+	}
+	if s.Port != 0 {
+		spec += ":" + strconv.Itoa(s.Port)
+	}
+	return spec
+}
+
+// ParseForwardSpec parses a --forward value: fn=<function>, name=<run name>
+// or run=<run id>, optionally followed by :<local port>.
+func ParseForwardSpec(value string) (ForwardSpec, error) {
+	var spec ForwardSpec
+	kind, target, ok := strings.Cut(value, "=")
+	if !ok || target == "" {
+		return spec, fmt.Errorf("invalid forward %q: expected fn=<function>, name=<run name> or run=<run id>, with an optional :<port>", value)
+	}
+	if i := strings.LastIndex(target, ":"); i >= 0 {
+		port, err := strconv.Atoi(target[i+1:])
+		if err != nil {
+			return spec, fmt.Errorf("invalid port in forward %q", value)
+		}
+		spec.Port = port
+		target = target[:i]
+	}
+	switch kind {
+	case "fn", "function":
+		spec.Function = target
+	case "name":
+		spec.Name = target
+	case "run":
+		spec.Run = target
+	default:
+		return spec, fmt.Errorf("invalid forward %q: unknown selector %q", value, kind)
+	}
+	return spec, spec.validate()
+}
+
+// LoadForwardManifest reads a manifest file, applying its default project to
+// the forwards.
+func LoadForwardManifest(path string) ([]ForwardSpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m ForwardManifest
+	if err := yaml.UnmarshalStrict(b, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if len(m.Forwards) == 0 {
+		return nil, fmt.Errorf("no forwards in %s", path)
+	}
+	for i := range m.Forwards {
+		if err := m.Forwards[i].validate(); err != nil {
+			return nil, fmt.Errorf("%s: forward %d: %w", path, i+1, err)
+		}
+		if m.Forwards[i].Project == "" {
+			m.Forwards[i].Project = m.Project
+		}
+	}
+	return m.Forwards, nil
+}
+
+func (s ForwardSpec) validate() error {
+	selectors := 0
+	for _, v := range []string{s.Run, s.Function, s.Name} {
+		if v != "" {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return fmt.Errorf("exactly one of run, function and name must be set")
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid local port %d", s.Port)
+	}
+	return nil
+}
+
+// StartPortForwards starts a port-forward per spec in the current process,
+// each with its own ServiceInfo cache, prints a table of the local ports
+// and shuts them all down when ctx is done or any of them fails. Specs
+// without a project use defaultProject.
+func StartPortForwards(ctx context.Context, specs []ForwardSpec, defaultProject string) error {
+	logger := utils.GetGlobalLogger()
+
+	ports := map[int]string{}
+	for _, spec := range specs {
+		if spec.Port == 0 {
+			continue
+		}
+		if other, ok := ports[spec.Port]; ok {
+			return fmt.Errorf("%s and %s both use local port %d", other, spec, spec.Port)
+		}
+		ports[spec.Port] = spec.String()
+	}
+
+	tokens := utils.CurrentTokenSource()
+	if authToken, _ := tokens.Token(); authToken == "" {
+		return fmt.Errorf("authorization token not available")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go tokens.KeepFresh(ctx)
+
+	forwarders := make([]*forwarder, 0, len(specs))
+	closeAll := func() {
+		for _, f := range forwarders {
+			f.ln.Close()
+		}
+	}
+	for _, spec := range specs {
+		project := spec.Project
+		if project == "" {
+			project = defaultProject
+		}
+		if project == "" {
+			closeAll()
+			return fmt.Errorf("%s: project is mandatory (use --project, the manifest or PROJECT_NAME)", spec)
+		}
+		runID, err := resolveRun(spec, project)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("%s: %w", spec, err)
+		}
+		f, err := newForwarder(ctx, project, runID, spec.Port)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("%s: %w", spec, err)
+		}
+		forwarders = append(forwarders, f)
+	}
+
+	printForwards(specs, forwarders)
+	logger.Success(fmt.Sprintf("%d port-forward(s) listening, press Ctrl-C to stop", len(forwarders)))
+
+	errCh := make(chan error, len(forwarders))
+	for i, f := range forwarders {
+		go func(spec ForwardSpec, f *forwarder) {
+			if err := f.server.Serve(f.ln); !errors.Is(err, http.ErrServerClosed) {
+				errCh <- fmt.Errorf("%s: %w", spec, err)
+				cancel()
+			}
+		}(specs[i], f)
+	}
+
+	<-ctx.Done()
+	logger.Info("Shutting down port-forwards...")
+	for _, f := range forwarders {
+		f.server.Shutdown(context.Background())
+	}
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return nil
+	}
+}
+
+// resolveRun returns the ID of the run selected by a spec: the most recent
+// RUNNING serve run for a function or run name.
+func resolveRun(spec ForwardSpec, project string) (string, error) {
+	switch {
+	case spec.Function != "":
+		return adapter.ResolveRunIDByFunctionName(project, spec.Function, "RUNNING", "serve")
+	case spec.Name != "":
+		return adapter.ResolveRunIDByName(project, spec.Name, "RUNNING", "serve")
+	default:
+		return spec.Run, nil
+	}
+}
+
+// printForwards prints the table of local port -> run -> base URL.
+func printForwards(specs []ForwardSpec, forwarders []*forwarder) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "LOCAL\tFORWARD\tRUN\tBASE URL")
+	for i, f := range forwarders {
+		fmt.Fprintf(w, "http://localhost:%d\t%s\t%s\t%s\n", f.port(), specs[i], f.runID, f.service.BaseURL)
+	}
+	w.Flush()
+}
@@ -95,24 +95,61 @@ type portForwardTarget struct {
 func StartPortForward(ctx context.Context, project string, runID string, localPort int) error {
 	logger := utils.GetGlobalLogger()
 
+	// The token is asked for on every request, as it is renewed while the
+	// port-forward runs
+	tokens := utils.CurrentTokenSource()
+	if authToken, _ := tokens.Token(); authToken == "" {
+		return fmt.Errorf("authorization token not available")
+	}
+	go tokens.KeepFresh(ctx)
+
+	f, err := newForwarder(ctx, project, runID, localPort)
+	if err != nil {
+		return err
+	}
+
+	logger.Success(fmt.Sprintf("Port-forward listening on localhost:%d", f.port()))
+	logger.Info(fmt.Sprintf("Run ID: %s -> Base URL: %s", runID, f.service.BaseURL))
+	logger.Info(fmt.Sprintf("Configure clients to use http://localhost:%d", f.port()))
+
+	// Handle context cancellation
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutting down port-forward...")
+		f.server.Shutdown(context.Background())
+	}()
+
+	return f.server.Serve(f.ln)
+}
+
+// forwarder is the local listener of one port-forward, with its own
+// ServiceInfo cache.
+type forwarder struct {
+	runID   string
+	service *ServiceInfo
+	server  *http.Server
+	ln      net.Listener
+}
+
+// newForwarder resolves the service of a run and listens on localPort for
+// the traffic to tunnel to it. The caller serves it.
+func newForwarder(ctx context.Context, project string, runID string, localPort int) (*forwarder, error) {
+	logger := utils.GetGlobalLogger()
+
 	// Get remote proxy URL from configuration
 	proxyURLStr := viper.GetString(keys.DhCoreProxy)
 	if proxyURLStr == "" {
-		return fmt.Errorf("proxy URL not configured")
+		return nil, fmt.Errorf("proxy URL not configured")
 	}
 
 	proxyURL, err := url.Parse(proxyURLStr)
 	if err != nil {
-		return fmt.Errorf("invalid proxy URL: %w", err)
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
 	}
 
 	logger.Step(fmt.Sprintf("Using proxy %s", proxyURL.String()))
 
-	// Get authorization token
-	authToken := viper.GetString(keys.DhCoreAccessToken)
-	if authToken == "" {
-		return fmt.Errorf("authorization token not available")
-	}
+	tokens := utils.CurrentTokenSource()
 
 	// Resolved host header value for the remote proxy (constant for lifetime)
 	proxyHost := proxyURL.Hostname()
@@ -125,15 +162,15 @@ func StartPortForward(ctx context.Context, project string, runID string, localPo
 	var mu sync.Mutex
 
 	if err := refreshServiceInfo(service, project, runID); err != nil {
-		return err
+		return nil, err
 	}
 
-	transport := &http.Transport{
-		// IMPORTANT for streaming / SSE
-		DisableCompression:    true,
-		ForceAttemptHTTP2:     false,
-		ResponseHeaderTimeout: 0,
-	}
+	// Start from the environment transport, for its TLS and proxy settings
+	transport := utils.HTTPTransport().Clone()
+	// IMPORTANT for streaming / SSE
+	transport.DisableCompression = true
+	transport.ForceAttemptHTTP2 = false
+	transport.ResponseHeaderTimeout = 0
 
 	var httpTransport http.RoundTripper = transport
 
@@ -167,6 +204,10 @@ func StartPortForward(ctx context.Context, project string, runID string, localPo
 			pr.Out.Header.Set("X-Proxy-Host", target.host)
 
 			// Authenticate with the remote proxy
+			authToken, err := tokens.Token()
+			if err != nil {
+				logger.Warn(err.Error())
+			}
 			pr.Out.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
 		},
 		Transport: httpTransport,
@@ -223,22 +264,15 @@ func StartPortForward(ctx context.Context, project string, runID string, localPo
 
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 
-	port := ln.Addr().(*net.TCPAddr).Port
-	logger.Success(fmt.Sprintf("Port-forward listening on localhost:%d", port))
-	logger.Info(fmt.Sprintf("Run ID: %s -> Base URL: %s", runID, service.BaseURL))
-	logger.Info(fmt.Sprintf("Configure clients to use http://localhost:%d", port))
-
-	// Handle context cancellation
-	go func() {
-		<-ctx.Done()
-		logger.Info("Shutting down port-forward...")
-		server.Shutdown(context.Background())
-	}()
+	return &forwarder{runID: runID, service: service, server: server, ln: ln}, nil
+}
 
-	return server.Serve(ln)
+// port returns the local port of the forwarder.
+func (f *forwarder) port() int {
+	return f.ln.Addr().(*net.TCPAddr).Port
 }
 
 // refreshServiceInfo fetches the run resource and extracts the baseURL
@@ -246,14 +280,20 @@ func refreshServiceInfo(service *ServiceInfo, project string, runID string) erro
 	logger := utils.GetGlobalLogger()
 	logger.Debug(fmt.Sprintf("Fetching run %s in project %s", runID, project))
 
+	tokens := utils.CurrentTokenSource()
+	authToken, err := tokens.Token()
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
 	// Build SDK config from viper
 	cfg := config.Config{
 		Core: config.CoreConfig{
-			BaseURL:     viper.GetString(keys.DhCoreEndpoint),
-			APIVersion:  viper.GetString(keys.DhCoreApiVersion),
-			AccessToken: viper.GetString(keys.DhCoreAccessToken),
+			BaseURL:     tokens.Get(keys.DhCoreEndpoint),
+			APIVersion:  tokens.Get(keys.DhCoreApiVersion),
+			AccessToken: authToken,
 		},
-		HTTPClient: utils.GetDebugHTTPClient(),
+		HTTPClient: utils.NewHTTPClient(0),
 	}
 
 	ctx := context.Background()
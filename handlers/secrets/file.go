@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File store parameters. The key is derived with PBKDF2-SHA256 and the
+// content sealed with AES-256-GCM.
+const (
+	fileVersion    = 1
+	fileIterations = 600_000
+	fileSaltSize   = 16
+	fileKeySize    = 32
+)
+
+// fileEnvelope is the on-disk format of the encrypted store.
+type fileEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// fileStore keeps every secret in a single passphrase-encrypted file. Reads
+// use the content decrypted on first use; changes decrypt the file again
+// first, so that the secrets stored meanwhile by other processes are kept.
+// Callers serialize changes with the INI lock.
+type fileStore struct {
+	path       string
+	passphrase func() (string, error)
+
+	loaded  bool
+	loadErr error
+	key     []byte
+	salt    []byte
+	entries map[string]string
+}
+
+func (f *fileStore) Name() string { return File }
+
+func (f *fileStore) Get(account string) (string, error) {
+	if err := f.load(); err != nil {
+		return "", err
+	}
+	v, ok := f.entries[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (f *fileStore) Set(account string, value string) error {
+	if err := f.reload(); err != nil {
+		return err
+	}
+	if old, ok := f.entries[account]; ok && old == value {
+		return nil
+	}
+	f.entries[account] = value
+	return f.save()
+}
+
+func (f *fileStore) Delete(account string) error {
+	if err := f.reload(); err != nil {
+		return err
+	}
+	if _, ok := f.entries[account]; !ok {
+		return nil
+	}
+	delete(f.entries, account)
+	return f.save()
+}
+
+// load decrypts the file on first use; a failure is remembered so a wrong
+// passphrase is only tried once.
+func (f *fileStore) load() error {
+	if f.loaded || f.loadErr != nil {
+		return f.loadErr
+	}
+	f.loadErr = f.read()
+	return f.loadErr
+}
+
+// reload decrypts the file again before a change.
+func (f *fileStore) reload() error {
+	if f.loadErr == nil {
+		f.loaded = false
+	}
+	return f.load()
+}
+
+func (f *fileStore) read() error {
+	f.entries = map[string]string{}
+
+	raw, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		f.loaded = true
+		if f.key != nil {
+			return nil
+		}
+		f.salt = make([]byte, fileSaltSize)
+		if _, err := rand.Read(f.salt); err != nil {
+			return err
+		}
+		f.key, err = f.deriveKey(f.salt, fileIterations)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read secret file: %w", err)
+	}
+
+	var env fileEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return fmt.Errorf("corrupt secret file %s: %w", f.path, err)
+	}
+	if env.Version != fileVersion || env.KDF != "pbkdf2-sha256" {
+		return fmt.Errorf("unsupported secret file format in %s", f.path)
+	}
+	key, err := f.deriveKey(env.Salt, env.Iterations)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return errors.New("cannot decrypt secret file: wrong passphrase or corrupt file")
+	}
+	if err := json.Unmarshal(plain, &f.entries); err != nil {
+		return fmt.Errorf("corrupt secret file %s: %w", f.path, err)
+	}
+	f.key, f.salt, f.loaded = key, env.Salt, true
+	return nil
+}
+
+func (f *fileStore) save() error {
+	plain, err := json.Marshal(f.entries)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(f.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(fileEnvelope{
+		Version:    fileVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: fileIterations,
+		Salt:       f.salt,
+		Nonce:      nonce,
+		Data:       gcm.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// derivedKeys caches the keys derived in this process, as the file is
+// decrypted again for every change.
+var derivedKeys = map[string][]byte{}
+
+func (f *fileStore) deriveKey(salt []byte, iterations int) ([]byte, error) {
+	if f.passphrase == nil {
+		return nil, errors.New("no passphrase available for the secret file")
+	}
+	pass, err := f.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	if pass == "" {
+		return nil, errors.New("empty passphrase for the secret file")
+	}
+	id := fmt.Sprintf("%s\x00%x\x00%d", pass, salt, iterations)
+	if key, ok := derivedKeys[id]; ok {
+		return key, nil
+	}
+	key, err := pbkdf2.Key(sha256.New, pass, salt, iterations, fileKeySize)
+	if err != nil {
+		return nil, err
+	}
+	derivedKeys[id] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
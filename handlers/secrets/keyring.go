@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyringStore talks to the OS keyring through its command line tool:
+// secret-tool (libsecret, Secret Service) on Linux and security on macOS.
+// Values are always passed on stdin, never as arguments.
+type keyringStore struct {
+	tool string
+}
+
+func newKeyringStore() (Store, error) {
+	tool := "secret-tool"
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "windows":
+		return nil, errors.New("the keyring secret store is not supported on Windows; use the file store")
+	}
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, fmt.Errorf("keyring not available (%s not found); use the file store", tool)
+	}
+	return &keyringStore{tool: path}, nil
+}
+
+func (k *keyringStore) Name() string { return Keyring }
+
+func (k *keyringStore) Get(account string) (string, error) {
+	if runtime.GOOS == "darwin" {
+		out, err := k.run("", "find-generic-password", "-s", ServiceName, "-a", account, "-w")
+		if err != nil {
+			return "", ErrNotFound
+		}
+		return strings.TrimSuffix(out, "\n"), nil
+	}
+	out, err := k.run("", "lookup", "service", ServiceName, "account", account)
+	if err != nil || out == "" {
+		// secret-tool exits 1 with no output for missing items
+		return "", ErrNotFound
+	}
+	return out, nil
+}
+
+func (k *keyringStore) Set(account string, value string) error {
+	if runtime.GOOS == "darwin" {
+		// security -i reads commands from stdin, keeping the value out of the
+		// process list.
+		cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", ServiceName, quoteArg(account), quoteArg(value))
+		_, err := k.run(cmd, "-i")
+		return err
+	}
+	_, err := k.run(value, "store", "--label", ServiceName+" "+account, "service", ServiceName, "account", account)
+	return err
+}
+
+func (k *keyringStore) Delete(account string) error {
+	if runtime.GOOS == "darwin" {
+		if _, err := k.run("", "delete-generic-password", "-s", ServiceName, "-a", account); err != nil {
+			return ErrNotFound
+		}
+		return nil
+	}
+	_, err := k.run("", "clear", "service", ServiceName, "account", account)
+	return err
+}
+
+func (k *keyringStore) run(stdin string, args ...string) (string, error) {
+	cmd := exec.Command(k.tool, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%s %s: %s", k.tool, args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// quoteArg quotes a word for the security interactive shell.
+func quoteArg(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package secrets stores credential values outside the INI file. The INI keeps
+// a reference such as "secret:keyring:prod/dhcore_access_token" in place of
+// the value.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Backend names.
+const (
+	Plaintext = "plaintext"
+	Keyring   = "keyring"
+	File      = "file"
+)
+
+// ServiceName identifies dhcli entries in the OS keyring.
+const ServiceName = "dhcli"
+
+const refPrefix = "secret:"
+
+// ErrNotFound is returned when no value is stored for an account.
+var ErrNotFound = errors.New("secret not found")
+
+// Store is a secret backend. Accounts are "<environment>/<key>".
+type Store interface {
+	Name() string
+	Get(account string) (string, error)
+	Set(account string, value string) error
+	Delete(account string) error
+}
+
+// Options configures the backends that need it.
+type Options struct {
+	// FilePath is the location of the encrypted file store.
+	FilePath string
+	// Passphrase returns the passphrase of the encrypted file store; it is
+	// called at most once per process.
+	Passphrase func() (string, error)
+}
+
+// Normalize validates a backend name; empty means plaintext.
+func Normalize(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", Plaintext:
+		return Plaintext, nil
+	case Keyring:
+		return Keyring, nil
+	case File:
+		return File, nil
+	}
+	return "", fmt.Errorf("unknown secret store %q (supported: plaintext, keyring, file)", name)
+}
+
+// Open returns the backend with the given name. Plaintext has no backend and
+// yields a nil Store.
+func Open(name string, opts Options) (Store, error) {
+	name, err := Normalize(name)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case Keyring:
+		return newKeyringStore()
+	case File:
+		if opts.FilePath == "" {
+			return nil, errors.New("no path configured for the encrypted secret file")
+		}
+		return &fileStore{path: opts.FilePath, passphrase: opts.Passphrase}, nil
+	}
+	return nil, nil
+}
+
+// Account builds the account name of a key in an environment.
+func Account(env string, key string) string {
+	return env + "/" + key
+}
+
+// Ref builds the INI reference of a stored secret.
+func Ref(store string, account string) string {
+	return refPrefix + store + ":" + account
+}
+
+// ParseRef splits an INI value into backend and account; ok is false for
+// plain values.
+func ParseRef(value string) (store string, account string, ok bool) {
+	rest, found := strings.CutPrefix(value, refPrefix)
+	if !found {
+		return "", "", false
+	}
+	store, account, found = strings.Cut(rest, ":")
+	if !found || account == "" {
+		return "", "", false
+	}
+	if _, err := Normalize(store); err != nil || store == Plaintext {
+		return "", "", false
+	}
+	return store, account, true
+}
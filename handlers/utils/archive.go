@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported archive formats.
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+)
+
+// NormalizeArchiveFormat validates an archive format name.
+func NormalizeArchiveFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "zip":
+		return ArchiveZip, nil
+	case "tar.gz", "tgz":
+		return ArchiveTarGz, nil
+	}
+	return "", fmt.Errorf("unknown archive format %q (supported: zip, tar.gz)", format)
+}
+
+// ArchiveExt returns the file extension of an archive format.
+func ArchiveExt(format string) string {
+	return "." + format
+}
+
+// ArchiveFormatFromName guesses the archive format from a file name, or
+// returns an empty string.
+func ArchiveFormatFromName(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveZip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveTarGz
+	}
+	return ""
+}
+
+// CreateArchive packs the regular files under srcDir into w. Entry names are
+// relative to srcDir; symlinks are skipped.
+func CreateArchive(srcDir string, format string, w io.Writer) error {
+	switch format {
+	case ArchiveZip:
+		zw := zip.NewWriter(w)
+		err := walkArchiveFiles(srcDir, func(path, name string, info os.FileInfo) error {
+			hdr, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			hdr.Method = zip.Deflate
+			fw, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+			return copyFileTo(path, fw)
+		})
+		if err != nil {
+			zw.Close()
+			return err
+		}
+		return zw.Close()
+
+	case ArchiveTarGz:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		err := walkArchiveFiles(srcDir, func(path, name string, info os.FileInfo) error {
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			return copyFileTo(path, tw)
+		})
+		if err != nil {
+			tw.Close()
+			gz.Close()
+			return err
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+	return fmt.Errorf("unknown archive format %q", format)
+}
+
+// ExtractArchive unpacks an archive into destDir and returns the paths of the
+// extracted files. Entries escaping destDir are rejected.
+func ExtractArchive(archivePath string, format string, destDir string) ([]string, error) {
+	var files []string
+	switch format {
+	case ArchiveZip:
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			if !f.Mode().IsRegular() {
+				continue // symlinks and devices are not restored
+			}
+			target, err := SafeJoin(destDir, f.Name)
+			if err != nil {
+				return files, err
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return files, err
+			}
+			err = writeExtracted(target, rc, f.Mode().Perm())
+			rc.Close()
+			if err != nil {
+				return files, err
+			}
+			files = append(files, target)
+		}
+		return files, nil
+
+	case ArchiveTarGz:
+		in, err := os.Open(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer in.Close()
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return files, nil
+			}
+			if err != nil {
+				return files, err
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			target, err := SafeJoin(destDir, hdr.Name)
+			if err != nil {
+				return files, err
+			}
+			if err := writeExtracted(target, tr, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return files, err
+			}
+			files = append(files, target)
+		}
+	}
+	return nil, fmt.Errorf("unknown archive format %q", format)
+}
+
+func walkArchiveFiles(srcDir string, fn func(path, name string, info os.FileInfo) error) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		return fn(path, filepath.ToSlash(rel), info)
+	})
+}
+
+func copyFileTo(path string, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// SafeJoin joins a slash-separated name, e.g. an archive entry or an object
+// key, to destDir, refusing names that would lead outside of it.
+func SafeJoin(destDir, name string) (string, error) {
+	target := filepath.Join(destDir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(destDir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q escapes the destination directory", name)
+	}
+	return target, nil
+}
+
+func writeExtracted(target string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if perm == 0 {
+		perm = 0o644
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
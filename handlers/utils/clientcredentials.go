@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+)
+
+// GrantClientCredentials marks environments logged in with a confidential
+// client: expired tokens are renewed by running the grant again.
+const GrantClientCredentials = "client_credentials"
+
+// ClientCredentialsLogin performs a client_credentials grant and persists the
+// tokens together with the client id and the path of the secret file, so the
+// grant can be repeated when the token expires. The secret itself is never
+// written to the INI file.
+func ClientCredentialsLogin(clientID string, secretFile string) error {
+	if clientID == "" {
+		return fmt.Errorf("client id is required")
+	}
+	if secretFile != "" {
+		abs, err := filepath.Abs(secretFile)
+		if err != nil {
+			return err
+		}
+		secretFile = abs
+	}
+
+	viper.Set(keys.DhCoreServiceClientId, clientID)
+	viper.Set(keys.DhCoreServiceSecretFile, secretFile)
+	if err := DoClientCredentials(); err != nil {
+		return err
+	}
+
+	viper.Set(keys.DhCoreGrantType, GrantClientCredentials)
+	return PersistCurrentEnv([]string{
+		keys.DhCoreGrantType,
+		keys.DhCoreServiceClientId,
+		keys.DhCoreServiceSecretFile,
+	})
+}
+
+// IsClientCredentials reports whether the current environment renews its
+// tokens with the client_credentials grant.
+func IsClientCredentials() bool {
+	return viper.GetString(keys.DhCoreGrantType) == GrantClientCredentials
+}
+
+// renewToken obtains a new access token with the grant the environment was
+// logged in with. The INI lock is held for the whole exchange, so concurrent
+// processes renew only once: the others wait and reuse the new token.
+func renewToken() error {
+	unlock, err := LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if adoptPersistedCredentials() {
+		logger.Info("Credentials already renewed by another process")
+		return nil
+	}
+	if IsClientCredentials() {
+		return DoClientCredentials()
+	}
+	return DoRefresh()
+}
+
+// DoClientCredentials runs the client_credentials grant of the current
+// environment and persists the new tokens.
+func DoClientCredentials() error {
+	logger.Info(fmt.Sprintf("Requesting client credentials token for %v ...", viper.GetString(keys.DhCoreEndpoint)))
+
+	tokenURL := viper.GetString(keys.OAuth2TokenEndpoint)
+	if tokenURL == "" {
+		return fmt.Errorf("oauth2_token_endpoint not configured")
+	}
+	clientID := viper.GetString(keys.DhCoreServiceClientId)
+	if clientID == "" {
+		return fmt.Errorf("%s not configured – please log in again", keys.DhCoreServiceClientId)
+	}
+	secret, err := clientSecret()
+	if err != nil {
+		return err
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// client_secret_basic: both parts are form-encoded first (RFC 6749 §2.3.1)
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(secret))
+
+	client := NewHTTPClient(15 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("client credentials request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("client credentials grant failed: %s - %s", resp.Status, string(body))
+	}
+
+	credKeys, err := ApplyTokenResponse(body)
+	if err != nil {
+		return fmt.Errorf("failed to apply token response: %w", err)
+	}
+	credKeys = append(credKeys, keys.CredentialsList)
+	if err := PersistCurrentEnv(credKeys); err != nil {
+		return fmt.Errorf("failed to persist tokens: %w", err)
+	}
+	return nil
+}
+
+// clientSecret reads the client secret from DHCORE_SERVICE_CLIENT_SECRET or
+// from the configured secret file.
+func clientSecret() (string, error) {
+	if s := viper.GetString(keys.DhCoreServiceSecret); s != "" {
+		return s, nil
+	}
+	path := viper.GetString(keys.DhCoreServiceSecretFile)
+	if path == "" {
+		return "", fmt.Errorf("no client secret: set %s or %s", strings.ToUpper(keys.DhCoreServiceSecretFile), strings.ToUpper(keys.DhCoreServiceSecret))
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read client secret file: %w", err)
+	}
+	secret := strings.TrimSpace(string(b))
+	if secret == "" {
+		return "", fmt.Errorf("client secret file %s is empty", path)
+	}
+	return secret, nil
+}
@@ -12,7 +12,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net/http"
 	"os"
 	"reflect"
 	"slices"
@@ -62,7 +61,7 @@ func LoadIni(createOnMissing bool) *ini.File {
 }
 
 func SaveIni(cfg *ini.File) {
-	if err := cfg.SaveTo(GetIniPath()); err != nil {
+	if err := SaveIniFile(cfg, GetIniPath()); err != nil {
 		logger.Error(fmt.Sprintf("Failed to update ini file: %v", err))
 		os.Exit(1)
 	}
@@ -95,7 +94,12 @@ func ReflectValue(v interface{}) string {
 	}
 }
 
+// TranslateFormat normalizes an output format; when none is given the
+// workspace or DHCLI_OUTPUT default applies.
 func TranslateFormat(format string) string {
+	if format == "" {
+		format = viper.GetString(keys.OutputFormat)
+	}
 	switch strings.ToLower(format) {
 	case "json":
 		return "json"
@@ -210,11 +214,7 @@ func GetStringValue(m map[string]interface{}, key string) string {
 }
 
 func FetchConfig(configURL string) (map[string]interface{}, error) {
-	// Use debug HTTP client if available, otherwise use default
-	client := GetDebugHTTPClient()
-	if client == nil {
-		client = &http.Client{}
-	}
+	client := NewHTTPClient(0)
 
 	resp, err := client.Get(configURL)
 	if err != nil {
@@ -266,5 +266,8 @@ func ResolveProject(project string) string {
 	if project != "" {
 		return project
 	}
-	return os.Getenv("PROJECT_NAME")
+	if p := os.Getenv("PROJECT_NAME"); p != "" {
+		return p
+	}
+	return viper.GetString(keys.ProjectName)
 }
@@ -28,7 +28,7 @@ const (
 // detectAuthMode returns the active authentication mode based on what is
 // currently stored in Viper.
 func detectAuthMode() authMode {
-	if viper.GetString(keys.DhCoreAccessToken) != "" || viper.GetString(keys.DhCoreRefreshToken) != "" {
+	if viper.GetString(keys.DhCoreAccessToken) != "" || viper.GetString(keys.DhCoreRefreshToken) != "" || IsClientCredentials() {
 		return authModeOAuth2
 	}
 	if viper.GetString(keys.DhCoreUser) != "" && viper.GetString(keys.DhCorePassword) != "" {
@@ -42,7 +42,8 @@ func detectAuthMode() authMode {
 // Three auth modes are supported:
 //   - OAuth2  (DHCORE_ACCESS_TOKEN or DHCORE_REFRESH_TOKEN present): sends a
 //     Bearer header; on 401 attempts a refresh_token grant and persists the new
-//     tokens.
+//     tokens. Environments logged in with client credentials re-run the
+//     client_credentials grant instead, as they have no refresh token.
 //   - Basic   (DHCORE_USER + DHCORE_PASSWORD present): sends an Authorization:
 //     Basic header; on 401 returns an error because the credentials are wrong
 //     and cannot be auto-renewed.
@@ -61,10 +62,7 @@ func CheckCredentials() error {
 
 	authURL := strings.TrimRight(endpoint, "/") + "/api/auth"
 
-	client := GetDebugHTTPClient()
-	if client == nil {
-		client = &http.Client{Timeout: 10 * time.Second}
-	}
+	client := NewHTTPClient(10 * time.Second)
 
 	req, err := http.NewRequest(http.MethodGet, authURL, nil)
 	if err != nil {
@@ -80,7 +78,7 @@ func CheckCredentials() error {
 			if expiresAt, err := time.Parse(time.RFC3339, expiresAtStr); err == nil {
 				if time.Until(expiresAt) < 5*time.Minute {
 					logger.Info("Token already expired or expires soon, refresh ...")
-					return DoRefresh()
+					return renewToken()
 				}
 			}
 		}
@@ -106,7 +104,7 @@ func CheckCredentials() error {
 
 	switch mode {
 	case authModeOAuth2:
-		return DoRefresh()
+		return renewToken()
 	case authModeBasic:
 		return fmt.Errorf("authentication failed: invalid username or password")
 	default: // authModePublic
@@ -151,10 +149,7 @@ func DoRefresh() error {
 		data.Set("scope", strings.Join(scopes, " "))
 	}
 
-	client := GetDebugHTTPClient()
-	if client == nil {
-		client = &http.Client{Timeout: 15 * time.Second}
-	}
+	client := NewHTTPClient(15 * time.Second)
 
 	resp, err := client.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
 	if err != nil {
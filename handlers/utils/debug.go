@@ -12,7 +12,8 @@ import (
 	"net/http/httputil"
 )
 
-// DebugTransport wraps http.RoundTripper to log requests and responses
+// DebugTransport wraps http.RoundTripper to log requests and responses. A nil
+// underlying transport means the transport of the current environment.
 type DebugTransport struct {
 	underlying http.RoundTripper
 }
@@ -30,7 +31,11 @@ func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 
 	// Execute request
-	resp, err := t.underlying.RoundTrip(req)
+	underlying := t.underlying
+	if underlying == nil {
+		underlying = HTTPTransport()
+	}
+	resp, err := underlying.RoundTrip(req)
 	if err != nil {
 		logger.Debug(fmt.Sprintf("Request error: %v", err))
 		return resp, err
@@ -55,9 +60,7 @@ func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 // CreateDebugHTTPClient creates an HTTP client with request/response logging
 func CreateDebugHTTPClient() *http.Client {
 	return &http.Client{
-		Transport: &DebugTransport{
-			underlying: http.DefaultTransport,
-		},
+		Transport: &DebugTransport{},
 	}
 }
 
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+)
+
+// hashPrefix tags the algorithm of the digests stored in status.files.
+const hashPrefix = "sha256:"
+
+// NewFileHasher returns the hash used for file integrity digests.
+func NewFileHasher() hash.Hash {
+	return sha256.New()
+}
+
+// FormatDigest renders a hasher state as it is stored in status.files.
+func FormatDigest(h hash.Hash) string {
+	return hashPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// HashReader computes the digest of everything read from r.
+func HashReader(r io.Reader) (string, error) {
+	h := NewFileHasher()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return FormatDigest(h), nil
+}
+
+// HashFile computes the digest of a local file.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return HashReader(f)
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"dhcli/keys"
+
+	"gopkg.in/ini.v1"
+)
+
+// DefaultIdentity names the credentials kept in the environment section
+// itself. Every other identity of an environment has its own section,
+// [<environment>@<identity>], holding its token set and credentials_list.
+const DefaultIdentity = "default"
+
+const identitySeparator = "@"
+
+var identityNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+var (
+	// selectedIdentity comes from the --identity flag.
+	selectedIdentity string
+	// activeIdentity is the identity of the loaded environment, identityEnv;
+	// empty for the default one.
+	activeIdentity string
+	identityEnv    string
+)
+
+// identityKeys are the non-credential keys that belong to a login rather
+// than to the environment.
+var identityKeys = map[string]bool{
+	keys.CredentialsList:         true,
+	keys.DhCoreGrantType:         true,
+	keys.DhCoreServiceClientId:   true,
+	keys.DhCoreServiceSecretFile: true,
+	keys.DhCoreUser:              true,
+	keys.DhCorePassword:          true,
+}
+
+// CheckIdentityName validates an identity name.
+func CheckIdentityName(name string) error {
+	if !identityNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid identity name %q", name)
+	}
+	return nil
+}
+
+// CheckEnvName rejects the names that cannot be used for an environment
+// section, such as those holding '@', which separates the identity from the
+// environment in the name of an identity section.
+func CheckEnvName(env string) error {
+	if env == "" {
+		return fmt.Errorf("environment name is required")
+	}
+	if strings.EqualFold(env, ini.DefaultSection) || strings.ContainsAny(env, "[]@\n") {
+		return fmt.Errorf("invalid environment name '%s'", env)
+	}
+	return nil
+}
+
+// SelectIdentity makes the next configuration load use the given identity,
+// whatever the one recorded for the environment.
+func SelectIdentity(name string) error {
+	if name != "" {
+		if err := CheckIdentityName(name); err != nil {
+			return err
+		}
+	}
+	selectedIdentity = name
+	return nil
+}
+
+// ActiveIdentity returns the identity of the loaded environment.
+func ActiveIdentity() string {
+	if activeIdentity == "" {
+		return DefaultIdentity
+	}
+	return activeIdentity
+}
+
+// IdentitySection returns the INI section holding the credentials of an
+// identity of env.
+func IdentitySection(env string, identity string) string {
+	if identity == "" || identity == DefaultIdentity {
+		return env
+	}
+	return env + identitySeparator + identity
+}
+
+// CredentialSection returns the INI section holding the credentials of env:
+// the section of the active identity for the loaded environment, the
+// environment section otherwise.
+func CredentialSection(env string) string {
+	if env != identityEnv {
+		return env
+	}
+	return IdentitySection(env, activeIdentity)
+}
+
+// SplitIdentitySection tells whether an INI section holds the credentials of
+// a non-default identity, and of which environment.
+func SplitIdentitySection(name string) (env string, identity string, ok bool) {
+	env, identity, ok = strings.Cut(name, identitySeparator)
+	return env, identity, ok && env != "" && identity != ""
+}
+
+// EnvIdentities returns the non-default identities with a section for env.
+func EnvIdentities(cfg *ini.File, env string) []string {
+	var out []string
+	for _, name := range cfg.SectionStrings() {
+		if e, identity, ok := SplitIdentitySection(name); ok && e == env {
+			out = append(out, identity)
+		}
+	}
+	return out
+}
+
+// RecordedIdentity returns the identity recorded for an environment section
+// by `dhcli use --as`.
+func RecordedIdentity(sec *ini.Section) string {
+	if sec.HasKey(keys.CurrentIdentity) {
+		if v := sec.Key(keys.CurrentIdentity).String(); v != "" {
+			return v
+		}
+	}
+	return DefaultIdentity
+}
+
+// IsIdentityKey reports whether a well-known key belongs to the credentials
+// of an identity rather than to its environment.
+func IsIdentityKey(name string) bool {
+	return identityKeys[name] || keys.CredentialKeys[name]
+}
+
+// isIdentityKey is IsIdentityKey extended to the credentials_list entries in
+// use.
+func isIdentityKey(name string, credentials map[string]bool) bool {
+	return IsIdentityKey(name) || credentials[name]
+}
+
+// sectionCredentials returns the entries of the credentials_list of a
+// section.
+func sectionCredentials(sec *ini.Section) map[string]bool {
+	out := map[string]bool{}
+	if sec.HasKey(keys.CredentialsList) {
+		for _, k := range SplitCSV(sec.Key(keys.CredentialsList).Value()) {
+			out[k] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// iniLockTimeout bounds how long a process waits for another one to release
+// the configuration lock.
+const iniLockTimeout = 60 * time.Second
+
+var (
+	iniLockMu    sync.Mutex
+	iniLockFile  *os.File
+	iniLockDepth int
+)
+
+// LockIni takes an exclusive advisory lock on the INI file, shared by every
+// dhcli process, and returns the function releasing it. The lock lives in a
+// separate <ini>.lock file, so the INI itself can be replaced atomically. It
+// is reentrant within the process: nested calls only release the lock once
+// the outermost caller does. Taking the lock drops the cached secrets, which
+// another process may have changed.
+func LockIni() (func(), error) {
+	iniLockMu.Lock()
+	defer iniLockMu.Unlock()
+
+	if iniLockDepth == 0 {
+		path := iniRealPath(GetIniPath()) + ".lock"
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open lock file: %w", err)
+		}
+		if err := acquireFileLock(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		iniLockFile = f
+		resetSecretCaches()
+	}
+	iniLockDepth++
+
+	var once sync.Once
+	return func() {
+		once.Do(unlockIni)
+	}, nil
+}
+
+func unlockIni() {
+	iniLockMu.Lock()
+	defer iniLockMu.Unlock()
+
+	iniLockDepth--
+	if iniLockDepth == 0 && iniLockFile != nil {
+		_ = releaseFileLock(iniLockFile)
+		iniLockFile.Close()
+		iniLockFile = nil
+	}
+}
+
+// acquireFileLock polls the lock until it is granted or iniLockTimeout
+// expires.
+func acquireFileLock(f *os.File) error {
+	deadline := time.Now().Add(iniLockTimeout)
+	waiting := false
+	for {
+		ok, err := tryFileLock(f)
+		if err != nil {
+			return fmt.Errorf("cannot lock %s: %w", f.Name(), err)
+		}
+		if ok {
+			return nil
+		}
+		if !waiting {
+			logger.Info("Waiting for another dhcli process to release the configuration ...")
+			waiting = true
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the lock on %s", f.Name())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// saveIniAtomic writes the INI to a temporary file next to it and renames it
+// into place, so readers never observe a partially written file.
+func saveIniAtomic(cfg *ini.File, path string) error {
+	path = iniRealPath(path)
+	mode := os.FileMode(0o600)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := cfg.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SaveIniFile locks the INI file and atomically replaces it with cfg. Callers
+// that read the file first should hold LockIni themselves, so the whole
+// load-modify-save cycle is protected.
+func SaveIniFile(cfg *ini.File, path string) error {
+	unlock, err := LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return saveIniAtomic(cfg, path)
+}
+
+// loadIniForUpdate loads the INI file for a modification. Only a missing file
+// yields an empty configuration: an unreadable one is never overwritten.
+func loadIniForUpdate(path string) (*ini.File, error) {
+	cfg, err := ini.Load(path)
+	if err == nil {
+		return cfg, nil
+	}
+	if _, statErr := os.Stat(path); errors.Is(statErr, os.ErrNotExist) {
+		return nil, os.ErrNotExist
+	}
+	return nil, fmt.Errorf("failed to read ini file: %w", err)
+}
+
+// iniRealPath follows a symlinked INI file, so that the rename replaces its
+// target instead of the link.
+func iniRealPath(path string) string {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		return p
+	}
+	return path
+}
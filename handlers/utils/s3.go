@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/spf13/viper"
+)
+
+// S3Object describes a single object found under an S3 prefix.
+type S3Object struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified string
+}
+
+// NewS3Client builds an S3 client from the aws_* keys of the current
+// environment, mirroring the configuration used by the SDK transfer service.
+// Credentials are refreshed transparently when aws_credentials_expiration
+// approaches, so long transfers survive the session token rotation.
+func NewS3Client(ctx context.Context) (*s3.Client, error) {
+	return newS3Client(ctx, viper.GetString("aws_endpoint_url"), viper.GetString("aws_region"),
+		awsconfig.WithCredentialsProvider(newS3CredentialsProvider()))
+}
+
+// NewS3ClientForEndpoint builds a client for another S3-compatible store.
+// Credentials come from the standard AWS chain (AWS_ACCESS_KEY_ID, shared
+// profiles, ...), not from the current environment.
+func NewS3ClientForEndpoint(ctx context.Context, endpointURL string, region string) (*s3.Client, error) {
+	return newS3Client(ctx, endpointURL, region)
+}
+
+func newS3Client(ctx context.Context, endpointURL string, region string, extra ...func(*awsconfig.LoadOptions) error) (*s3.Client, error) {
+	opts := append([]func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(region)}, extra...)
+	if GetDebugHTTPClient() != nil {
+		opts = append(opts, awsconfig.WithHTTPClient(NewHTTPClient(0)))
+	} else if transport != nil {
+		// A buildable client lets the SDK still apply AWS_CA_BUNDLE.
+		opts = append(opts, awsconfig.WithHTTPClient(awshttp.NewBuildableClient().WithTransportOptions(func(t *http.Transport) {
+			t.Proxy = transport.Proxy
+			if transport.TLSClientConfig != nil {
+				t.TLSClientConfig = transport.TLSClientConfig.Clone()
+			}
+		})))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		// S3-compatible stores often lack the newer checksum headers.
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+		if endpointURL != "" {
+			o.BaseEndpoint = aws.String(endpointURL)
+			o.UsePathStyle = true // required by most S3-compatible stores
+		}
+	}), nil
+}
+
+// ListS3Objects returns every object stored under prefix, skipping folder
+// placeholders. A key without trailing slash lists just that object (and any
+// sibling sharing the prefix, which callers filter when needed).
+func ListS3Objects(ctx context.Context, client *s3.Client, bucket, prefix string) ([]S3Object, error) {
+	var out []S3Object
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in s3://%s/%s: %w", bucket, prefix, err)
+		}
+		for _, obj := range page.Contents {
+			if isFolderPlaceholder(obj) {
+				continue
+			}
+			out = append(out, toS3Object(obj))
+		}
+	}
+	return out, nil
+}
+
+// DownloadS3Object streams the content of a single object into w.
+func DownloadS3Object(ctx context.Context, client *s3.Client, bucket, key string, w io.Writer) error {
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object s3://%s/%s: %w", bucket, key, err)
+	}
+	defer out.Body.Close()
+
+	if _, err := io.Copy(w, out.Body); err != nil {
+		return fmt.Errorf("failed to read object s3://%s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// HeadS3Object returns the size of a single object.
+func HeadS3Object(ctx context.Context, client *s3.Client, bucket, key string) (int64, error) {
+	out, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat object s3://%s/%s: %w", bucket, key, err)
+	}
+	return aws.ToInt64(out.ContentLength), nil
+}
+
+// ReadS3Range returns length bytes of an object starting at offset.
+func ReadS3Range(ctx context.Context, client *s3.Client, bucket, key string, offset, length int64) ([]byte, error) {
+	if length <= 0 {
+		return nil, nil
+	}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", bucket, key, err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+// UploadS3Object uploads r to bucket/key. Large bodies are sent as multipart
+// uploads; every part is signed with the current credentials.
+func UploadS3Object(ctx context.Context, client *s3.Client, bucket, key string, r io.Reader, contentType string) error {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if _, err := manager.NewUploader(client).Upload(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// CopyS3Object copies an object within the same store without moving the
+// data through the client. Objects larger than 5 GB cannot be copied this
+// way and callers should stream them instead.
+func CopyS3Object(ctx context.Context, client *s3.Client, srcBucket, srcKey, dstBucket, dstKey string) error {
+	_, err := client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(dstBucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(srcBucket + "/" + escapeKey(srcKey)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy s3://%s/%s to s3://%s/%s: %w", srcBucket, srcKey, dstBucket, dstKey, err)
+	}
+	return nil
+}
+
+// NormalizeETag strips the surrounding quotes S3 puts around ETag values.
+func NormalizeETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// escapeKey URL-encodes every segment of an object key, keeping the slashes.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func isFolderPlaceholder(obj s3types.Object) bool {
+	return strings.HasSuffix(aws.ToString(obj.Key), "/") && aws.ToInt64(obj.Size) == 0
+}
+
+func toS3Object(obj s3types.Object) S3Object {
+	o := S3Object{
+		Key:  aws.ToString(obj.Key),
+		Size: aws.ToInt64(obj.Size),
+		ETag: NormalizeETag(aws.ToString(obj.ETag)),
+	}
+	if obj.LastModified != nil {
+		o.LastModified = obj.LastModified.UTC().Format("2006-01-02T15:04:05Z07:00")
+	}
+	return o
+}
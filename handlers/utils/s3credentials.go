@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/viper"
+)
+
+// s3RefreshMargin is how long before aws_credentials_expiration the CLI asks
+// the token endpoint for new S3 credentials.
+const s3RefreshMargin = 5 * time.Minute
+
+// s3MinRefreshInterval prevents hammering the token endpoint when a refresh
+// does not extend the credentials lifetime.
+const s3MinRefreshInterval = time.Minute
+
+// s3CredentialKeys are the keys describing the pass-through S3 credentials.
+var s3CredentialKeys = []string{
+	"aws_access_key_id",
+	"aws_secret_access_key",
+	"aws_session_token",
+	"aws_credentials_expiration",
+	"aws_endpoint_url",
+	"aws_region",
+}
+
+// S3CredentialsExpiration returns the expiry of the pass-through S3
+// credentials, if the token response provided one.
+func S3CredentialsExpiration() (time.Time, bool) {
+	raw := viper.GetString("aws_credentials_expiration")
+	if raw == "" {
+		return time.Time{}, false
+	}
+	exp, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return exp, true
+}
+
+// EnsureS3Credentials refreshes the session when the S3 credentials expire
+// within the refresh margin. Call it before handing credentials to code that
+// cannot refresh them itself (e.g. the SDK transfer service).
+func EnsureS3Credentials() error {
+	exp, ok := S3CredentialsExpiration()
+	if !ok || time.Until(exp) > s3RefreshMargin {
+		return nil
+	}
+	logger.Info(fmt.Sprintf("S3 credentials expire at %s, refreshing ...", exp.Local().Format(time.RFC3339)))
+	return renewToken()
+}
+
+// s3CredentialsProvider serves the aws_* keys of the current environment and
+// refreshes them through renewToken when they are about to expire. Wrapped in
+// an aws.CredentialsCache, every S3 request (including each part of a
+// multipart upload) picks up the refreshed credentials without restarting the
+// transfer.
+type s3CredentialsProvider struct {
+	mu          sync.Mutex
+	lastRefresh time.Time
+}
+
+func newS3CredentialsProvider() aws.CredentialsProvider {
+	return aws.NewCredentialsCache(&s3CredentialsProvider{}, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = s3RefreshMargin
+	})
+}
+
+// Retrieve implements aws.CredentialsProvider.
+func (p *s3CredentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if exp, ok := S3CredentialsExpiration(); ok && time.Until(exp) <= s3RefreshMargin &&
+		time.Since(p.lastRefresh) >= s3MinRefreshInterval {
+		if err := EnsureS3Credentials(); err != nil {
+			return aws.Credentials{}, fmt.Errorf("failed to refresh S3 credentials: %w", err)
+		}
+		p.lastRefresh = time.Now()
+	}
+
+	creds := aws.Credentials{
+		AccessKeyID:     viper.GetString("aws_access_key_id"),
+		SecretAccessKey: viper.GetString("aws_secret_access_key"),
+		SessionToken:    viper.GetString("aws_session_token"),
+		Source:          "dhcli",
+	}
+	if exp, ok := S3CredentialsExpiration(); ok {
+		creds.CanExpire = true
+		creds.Expires = exp
+	}
+	return creds, nil
+}
@@ -0,0 +1,290 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dhcli/handlers/secrets"
+	"dhcli/keys"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/viper"
+	"gopkg.in/ini.v1"
+)
+
+// SecretPassphraseEnv holds the passphrase of the encrypted secret file for
+// non-interactive use.
+const SecretPassphraseEnv = "DHCLI_SECRET_PASSPHRASE"
+
+var (
+	openStores = map[string]secrets.Store{}
+	// resolvedRefs remembers the values read through INI references, so
+	// unchanged credentials are not written to the backend again.
+	resolvedRefs = map[string]string{}
+	passphrase   string
+)
+
+// resetSecretCaches forgets the open stores and the resolved references, so
+// that the values another process stored are read again. It is called when
+// the INI lock is taken.
+func resetSecretCaches() {
+	openStores = map[string]secrets.Store{}
+	resolvedRefs = map[string]string{}
+}
+
+// SecretStoreName returns the configured secret backend (secret_store),
+// defaulting to plaintext.
+func SecretStoreName() (string, error) {
+	return secrets.Normalize(viper.GetString(keys.SecretStore))
+}
+
+// SecretFilePath returns the location of the encrypted secret file: the
+// secret_store_file key, or .dhcore.secrets next to the INI file.
+func SecretFilePath() string {
+	if p := viper.GetString(keys.SecretStoreFile); p != "" {
+		return p
+	}
+	return filepath.Join(filepath.Dir(GetIniPath()), ".dhcore.secrets")
+}
+
+func openSecretStore(name string) (secrets.Store, error) {
+	if s, ok := openStores[name]; ok {
+		return s, nil
+	}
+	s, err := secrets.Open(name, secrets.Options{FilePath: SecretFilePath(), Passphrase: secretPassphrase})
+	if err != nil {
+		return nil, err
+	}
+	openStores[name] = s
+	return s, nil
+}
+
+// secretPassphrase reads the file store passphrase from the environment, or
+// prompts for it when a terminal is attached.
+func secretPassphrase() (string, error) {
+	if passphrase != "" {
+		return passphrase, nil
+	}
+	if v := os.Getenv(SecretPassphraseEnv); v != "" {
+		passphrase = v
+		return v, nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("the secret file is encrypted: set %s", SecretPassphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, "Secret store passphrase: ")
+	b, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("cannot read passphrase: %w", err)
+	}
+	passphrase = string(b)
+	return passphrase, nil
+}
+
+// ResolveSecretRef returns the value behind an INI reference, or the value
+// itself when it is not a reference.
+func ResolveSecretRef(value string) (string, error) {
+	storeName, account, ok := secrets.ParseRef(value)
+	if !ok {
+		return value, nil
+	}
+	if v, ok := resolvedRefs[value]; ok {
+		return v, nil
+	}
+	store, err := openSecretStore(storeName)
+	if err != nil {
+		return "", err
+	}
+	v, err := store.Get(account)
+	if errors.Is(err, secrets.ErrNotFound) {
+		v, err = "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s from the %s store: %w", account, storeName, err)
+	}
+	resolvedRefs[value] = v
+	return v, nil
+}
+
+// secretIniValue stores a credential in the configured backend and returns
+// what the INI should hold: a reference, or the value itself in plaintext
+// mode. current is the value found in the INI before the update.
+func secretIniValue(env string, key string, value string, current string) (string, error) {
+	storeName, err := SecretStoreName()
+	if err != nil {
+		return "", err
+	}
+	oldStore, oldAccount, wasRef := secrets.ParseRef(current)
+
+	if storeName == secrets.Plaintext {
+		if wasRef {
+			deleteSecret(oldStore, oldAccount)
+		}
+		return value, nil
+	}
+
+	account := secrets.Account(env, key)
+	ref := secrets.Ref(storeName, account)
+	if wasRef && current == ref {
+		if v, err := ResolveSecretRef(ref); err == nil && v == value {
+			return ref, nil
+		}
+	}
+
+	store, err := openSecretStore(storeName)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		if err := store.Delete(account); err != nil && !errors.Is(err, secrets.ErrNotFound) {
+			return "", err
+		}
+		return "", nil
+	}
+	if err := store.Set(account, value); err != nil {
+		return "", fmt.Errorf("cannot store %s in the %s store: %w", key, storeName, err)
+	}
+	resolvedRefs[ref] = value
+	if wasRef && current != ref {
+		deleteSecret(oldStore, oldAccount)
+	}
+	return ref, nil
+}
+
+func deleteSecret(storeName string, account string) {
+	store, err := openSecretStore(storeName)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Cannot remove %s from the %s store: %v", account, storeName, err))
+		return
+	}
+	if err := store.Delete(account); err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		logger.Warn(fmt.Sprintf("Cannot remove %s from the %s store: %v", account, storeName, err))
+	}
+}
+
+// DeleteSectionSecrets removes from their backend the secrets referenced by
+// an INI section, e.g. before the section is deleted.
+func DeleteSectionSecrets(sec *ini.Section) {
+	for _, k := range sec.Keys() {
+		if storeName, account, ok := secrets.ParseRef(k.Value()); ok {
+			deleteSecret(storeName, account)
+		}
+	}
+}
+
+// MoveSectionSecrets stores the secrets referenced by an INI section under
+// the accounts of env, e.g. after the section is renamed, and updates the
+// references in the section.
+func MoveSectionSecrets(sec *ini.Section, env string) error {
+	for _, k := range sec.Keys() {
+		storeName, account, ok := secrets.ParseRef(k.Value())
+		if !ok {
+			continue
+		}
+		target := secrets.Account(env, k.Name())
+		if account == target {
+			continue
+		}
+		store, err := openSecretStore(storeName)
+		if err != nil {
+			return err
+		}
+		value, err := store.Get(account)
+		if errors.Is(err, secrets.ErrNotFound) {
+			k.SetValue("")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read %s from the %s store: %w", account, storeName, err)
+		}
+		if err := store.Set(target, value); err != nil {
+			return fmt.Errorf("cannot store %s in the %s store: %w", target, storeName, err)
+		}
+		k.SetValue(secrets.Ref(storeName, target))
+		deleteSecret(storeName, account)
+	}
+	return nil
+}
+
+// ClearSectionCredentials removes from an INI section every key listed in its
+// credentials_list, together with the list itself, the grant marker and any
+// secret they reference. It returns the keys removed.
+func ClearSectionCredentials(sec *ini.Section) []string {
+	names := []string{keys.CredentialsList, keys.DhCoreGrantType}
+	if sec.HasKey(keys.CredentialsList) {
+		names = append(SplitCSV(sec.Key(keys.CredentialsList).String()), names...)
+	}
+	var removed []string
+	for _, name := range names {
+		if !sec.HasKey(name) {
+			continue
+		}
+		if storeName, account, ok := secrets.ParseRef(sec.Key(name).Value()); ok {
+			deleteSecret(storeName, account)
+		}
+		sec.DeleteKey(name)
+		removed = append(removed, name)
+	}
+	return removed
+}
+
+// MigrateSecretStore moves the credentials of every environment to the given
+// backend and records it as secret_store in [DEFAULT]. It returns the number
+// of values moved.
+func MigrateSecretStore(name string) (int, error) {
+	name, err := secrets.Normalize(name)
+	if err != nil {
+		return 0, err
+	}
+	unlock, err := LockIni()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	iniPath := GetIniPath()
+	cfg, err := ini.Load(iniPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read ini file: %w", err)
+	}
+
+	// The target store applies from now on, whatever the INI says.
+	viper.Set(keys.SecretStore, name)
+
+	moved := 0
+	for _, sec := range cfg.Sections() {
+		env := sec.Name()
+		for key := range sectionCredentials(sec) {
+			if !sec.HasKey(key) {
+				continue
+			}
+			k := sec.Key(key)
+			value, err := ResolveSecretRef(k.Value())
+			if err != nil {
+				return moved, err
+			}
+			iniValue, err := secretIniValue(env, key, value, k.Value())
+			if err != nil {
+				return moved, err
+			}
+			if iniValue != k.Value() {
+				k.SetValue(iniValue)
+				moved++
+			}
+		}
+	}
+
+	if name == secrets.Plaintext {
+		cfg.Section("DEFAULT").DeleteKey(keys.SecretStore)
+	} else {
+		cfg.Section("DEFAULT").Key(keys.SecretStore).SetValue(name)
+	}
+	return moved, saveIniAtomic(cfg, iniPath)
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// tokenRefreshLeeway is how long before dhcore_expires_at a token is
+	// renewed.
+	tokenRefreshLeeway = 5 * time.Minute
+	// tokenRetryInterval is how often KeepFresh checks the token when its
+	// expiry is unknown or a renewal failed.
+	tokenRetryInterval = 30 * time.Second
+)
+
+// TokenSource hands out the access token of the current environment to
+// long-running commands, which must ask for it on every request instead of
+// reading it once. The token is renewed, and the new tokens persisted, when
+// it is about to expire. It is safe for concurrent use: LockIni only
+// serializes processes, so renewals within the process are serialized here.
+type TokenSource struct {
+	mu sync.Mutex
+}
+
+var tokenSource = &TokenSource{}
+
+// CurrentTokenSource returns the token source of the current environment.
+func CurrentTokenSource() *TokenSource {
+	return tokenSource
+}
+
+// Token returns a valid access token, renewing it first when it expires
+// within tokenRefreshLeeway. On failure the current token is returned along
+// with the error, so callers may still try it. Environments without OAuth2
+// credentials get an empty token.
+func (ts *TokenSource) Token() (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if detectAuthMode() != authModeOAuth2 {
+		return viper.GetString(keys.DhCoreAccessToken), nil
+	}
+	if expiresAt, ok := tokenExpiry(); (ok && time.Until(expiresAt) < tokenRefreshLeeway) ||
+		viper.GetString(keys.DhCoreAccessToken) == "" {
+		logger.Info("Token expires soon, refresh ...")
+		if err := renewToken(); err != nil {
+			return viper.GetString(keys.DhCoreAccessToken), fmt.Errorf("token refresh failed: %w", err)
+		}
+	}
+	return viper.GetString(keys.DhCoreAccessToken), nil
+}
+
+// Get returns a configuration value. Goroutines running alongside KeepFresh
+// must read the configuration through it, as a renewal updates Viper.
+func (ts *TokenSource) Get(key string) string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return viper.GetString(key)
+}
+
+// S3Credentials returns the aws_* values of the current environment,
+// renewing them first when they expire within the refresh margin. On failure
+// the current values are returned along with the error.
+func (ts *TokenSource) S3Credentials() (map[string]string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	err := EnsureS3Credentials()
+	values := make(map[string]string, len(s3CredentialKeys))
+	for _, k := range s3CredentialKeys {
+		values[k] = viper.GetString(k)
+	}
+	return values, err
+}
+
+// KeepFresh renews the token in the background shortly before it expires,
+// until ctx is done, so that connections opened later, e.g. on reconnect,
+// find a valid token without waiting for the token endpoint.
+func (ts *TokenSource) KeepFresh(ctx context.Context) {
+	for {
+		wait := tokenRetryInterval
+		ts.mu.Lock()
+		if expiresAt, ok := tokenExpiry(); ok {
+			if d := time.Until(expiresAt) - tokenRefreshLeeway; d > wait {
+				wait = d
+			}
+		}
+		ts.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if _, err := ts.Token(); err != nil {
+			logger.Warn(err.Error())
+		}
+	}
+}
+
+// tokenExpiry returns dhcore_expires_at, when known.
+func tokenExpiry() (time.Time, bool) {
+	expiresAt, err := time.Parse(time.RFC3339, viper.GetString(keys.DhCoreExpiresAt))
+	return expiresAt, err == nil
+}
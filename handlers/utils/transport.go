@@ -0,0 +1,293 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+	"gopkg.in/ini.v1"
+)
+
+// TransportSettings are the per-environment options of outbound connections.
+type TransportSettings struct {
+	CAFile             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	HTTPProxy          string
+	NoProxy            string
+}
+
+// transport is the transport configured for the current environment; nil
+// means http.DefaultTransport.
+var transport *http.Transport
+
+// proxyKeyAliases maps the plain proxy key names, also accepted in INI
+// sections, to the keys holding them. The plain names are never read through
+// Viper, which would return the HTTP_PROXY and NO_PROXY variables instead.
+var proxyKeyAliases = map[string]string{
+	"http_proxy": keys.HTTPProxy,
+	"no_proxy":   keys.NoProxy,
+}
+
+// applyProxyAliases moves the values of INI keys under their plain proxy
+// names to the keys holding them, unless those are set too.
+func applyProxyAliases(values map[string]string) {
+	for alias, key := range proxyKeyAliases {
+		v, ok := values[alias]
+		if !ok {
+			continue
+		}
+		delete(values, alias)
+		if values[key] == "" {
+			values[key] = v
+		}
+	}
+}
+
+// ReadTransportSettings builds the settings from a key lookup, e.g.
+// viper.GetString or the values of an INI section.
+func ReadTransportSettings(get func(key string) string) (TransportSettings, error) {
+	s := TransportSettings{
+		CAFile:     get(keys.TLSCAFile),
+		ClientCert: get(keys.TLSClientCert),
+		ClientKey:  get(keys.TLSClientKey),
+		HTTPProxy:  get(keys.HTTPProxy),
+		NoProxy:    get(keys.NoProxy),
+	}
+	if v := get(keys.TLSInsecureSkipVerify); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("invalid %s: %q", keys.TLSInsecureSkipVerify, v)
+		}
+		s.InsecureSkipVerify = b
+	}
+	return s, nil
+}
+
+// Values returns the settings as INI values, omitting the unset ones.
+func (s TransportSettings) Values() map[string]string {
+	values := map[string]string{
+		keys.TLSCAFile:     s.CAFile,
+		keys.TLSClientCert: s.ClientCert,
+		keys.TLSClientKey:  s.ClientKey,
+		keys.HTTPProxy:     s.HTTPProxy,
+		keys.NoProxy:       s.NoProxy,
+	}
+	if s.InsecureSkipVerify {
+		values[keys.TLSInsecureSkipVerify] = "true"
+	}
+	for k, v := range values {
+		if v == "" {
+			delete(values, k)
+		}
+	}
+	return values
+}
+
+// Merge returns the settings with the ones set in over replacing them.
+func (s TransportSettings) Merge(over TransportSettings) TransportSettings {
+	pick := func(a, b string) string {
+		if b != "" {
+			return b
+		}
+		return a
+	}
+	return TransportSettings{
+		CAFile:             pick(s.CAFile, over.CAFile),
+		ClientCert:         pick(s.ClientCert, over.ClientCert),
+		ClientKey:          pick(s.ClientKey, over.ClientKey),
+		InsecureSkipVerify: s.InsecureSkipVerify || over.InsecureSkipVerify,
+		HTTPProxy:          pick(s.HTTPProxy, over.HTTPProxy),
+		NoProxy:            pick(s.NoProxy, over.NoProxy),
+	}
+}
+
+// AbsPaths returns the settings with the file paths made absolute, so they
+// still work from another directory once stored.
+func (s TransportSettings) AbsPaths() (TransportSettings, error) {
+	for _, p := range []*string{&s.CAFile, &s.ClientCert, &s.ClientKey} {
+		if *p == "" {
+			continue
+		}
+		abs, err := filepath.Abs(*p)
+		if err != nil {
+			return s, err
+		}
+		*p = abs
+	}
+	return s, nil
+}
+
+// SectionTransportSettings reads the settings stored in an INI section.
+func SectionTransportSettings(sec *ini.Section) (TransportSettings, error) {
+	values := make(map[string]string)
+	for _, k := range sec.Keys() {
+		values[k.Name()] = k.String()
+	}
+	applyProxyAliases(values)
+	return ReadTransportSettings(func(key string) string {
+		return values[key]
+	})
+}
+
+// ConfigureTransport installs the transport used by every HTTP client of the
+// CLI from the settings of the current environment.
+func ConfigureTransport(s TransportSettings) error {
+	if s == (TransportSettings{}) {
+		transport = nil
+		return nil
+	}
+	t, err := NewTransport(s)
+	if err != nil {
+		return err
+	}
+	transport = t
+	return nil
+}
+
+// ConfigureTransportFromConfig calls ConfigureTransport with the settings
+// loaded into Viper.
+func ConfigureTransportFromConfig() error {
+	s, err := ReadTransportSettings(viper.GetString)
+	if err != nil {
+		return err
+	}
+	return ConfigureTransport(s)
+}
+
+// NewTransport returns a copy of http.DefaultTransport applying the settings.
+func NewTransport(s TransportSettings) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if s.CAFile != "" || s.ClientCert != "" || s.ClientKey != "" || s.InsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: s.InsecureSkipVerify,
+		}
+		if s.CAFile != "" {
+			pem, err := os.ReadFile(s.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read CA bundle: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", s.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if s.ClientCert != "" || s.ClientKey != "" {
+			if s.ClientCert == "" || s.ClientKey == "" {
+				return nil, fmt.Errorf("%s and %s must be set together", keys.TLSClientCert, keys.TLSClientKey)
+			}
+			cert, err := tls.LoadX509KeyPair(s.ClientCert, s.ClientKey)
+			if err != nil {
+				return nil, fmt.Errorf("cannot load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		t.TLSClientConfig = tlsConfig
+	}
+
+	if s.HTTPProxy != "" {
+		proxyURL, err := url.Parse(s.HTTPProxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid %s: %q", keys.HTTPProxy, s.HTTPProxy)
+		}
+		noProxy := SplitCSV(s.NoProxy)
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL, noProxy) {
+				return nil, nil
+			}
+			return proxyURL, nil
+		}
+	}
+	return t, nil
+}
+
+// HTTPTransport returns the transport of the current environment.
+func HTTPTransport() *http.Transport {
+	if transport != nil {
+		return transport
+	}
+	return http.DefaultTransport.(*http.Transport)
+}
+
+// NewHTTPClient returns a client using the transport of the current
+// environment, logging the exchanges in debug mode. A zero timeout means no
+// timeout.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	var rt http.RoundTripper = HTTPTransport()
+	if debugHTTPClient != nil {
+		rt = &DebugTransport{underlying: rt}
+	}
+	return &http.Client{Transport: rt, Timeout: timeout}
+}
+
+// bypassProxy matches a URL against dhcore_no_proxy entries: "*", host names
+// (matching their subdomains too), IP addresses and CIDR ranges, each
+// optionally with a port. Like with HTTP_PROXY, loopback addresses are never
+// proxied.
+func bypassProxy(u *url.URL, noProxy []string) bool {
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" {
+		return true
+	}
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443", "ws": "80", "wss": "443"}[u.Scheme]
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, "*")
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
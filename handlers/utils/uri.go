@@ -12,9 +12,14 @@ type ParsedPath struct {
 	Host     string
 	Path     string
 	Filename string
+	// Archive is the archive format of compound schemes such as
+	// zip+s3://bucket/key.zip: the object is a single archive of a directory.
+	Archive string
 }
 
-// ParsePath parses any kind of path: S3, HTTP, local (absolute or relative)
+// ParsePath parses any kind of path: S3, HTTP, local (absolute or relative).
+// Compound schemes (zip+s3, tar.gz+s3) set Archive and leave the transport
+// scheme in Scheme.
 func ParsePath(input string) (*ParsedPath, error) {
 	// Try parsing as URI
 	parsed, err := url.Parse(input)
@@ -27,8 +32,19 @@ func ParsePath(input string) (*ParsedPath, error) {
 	// If there's a scheme (e.g. s3, https), treat it as URI
 	if parsed.Scheme != "" {
 		result.Scheme = parsed.Scheme
+		// Other compound schemes (e.g. git+https) are kept as they are.
+		if i := strings.LastIndex(parsed.Scheme, "+"); i > 0 {
+			if format, err := NormalizeArchiveFormat(parsed.Scheme[:i]); err == nil {
+				result.Archive = format
+				result.Scheme = parsed.Scheme[i+1:]
+			}
+		}
 		result.Host = parsed.Host
 		result.Path = strings.TrimPrefix(parsed.Path, "/")
+		if result.Scheme == "file" {
+			// file:// URLs carry an absolute filesystem path.
+			result.Path = parsed.Path
+		}
 		result.Filename = filepath.Base(parsed.Path)
 		return result, nil
 	}
@@ -6,11 +6,14 @@ package utils
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
 
+	"dhcli/handlers/secrets"
 	"dhcli/keys"
 
 	"github.com/spf13/viper"
@@ -20,6 +23,12 @@ import (
 // InternalKeys are CLI-only keys that must never be added as new INI entries.
 var InternalKeys = map[string]bool{}
 
+// loadedSections remembers the values of the sections loaded into Viper, by
+// section name, to tell the keys changed by this process from the ones
+// another process changed in the meantime. Secret references are resolved,
+// as the reference stays the same when the secret behind it changes.
+var loadedSections = map[string]map[string]string{}
+
 // SetupViperEnv configures Viper to automatically bind environment variables.
 // Key foo_bar maps to env var FOO_BAR.
 func SetupViperEnv() {
@@ -29,43 +38,205 @@ func SetupViperEnv() {
 
 // PersistToIni updates every existing key in the named INI section from the
 // current Viper value, then upserts any explicitly-provided additional keys.
-// All values are written as-is, including empty strings.
+// All values are written as-is, including empty strings, except the keys in
+// credentials_list, which go to the configured secret store and are replaced
+// by a reference. When a named identity is active, its credentials go to its
+// own section instead.
+// Existing keys that another process changed since the section was loaded are
+// left alone unless they are among the additional keys. The file is updated
+// under the INI lock and replaced atomically.
 // If the INI file does not yet exist a new one is created.
 func PersistToIni(iniPath, envName string, additionalKeys []string) error {
-	cfg, err := ini.Load(iniPath)
+	unlock, err := LockIni()
 	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg, err := loadIniForUpdate(iniPath)
+	if errors.Is(err, os.ErrNotExist) {
 		cfg = ini.Empty()
 		cfg.Section("DEFAULT").Key(keys.CurrentEnvironment).SetValue(envName)
+	} else if err != nil {
+		return err
 	}
 
 	sec := cfg.Section(envName)
 
-	// Update all existing section keys from Viper.
+	explicit := make(map[string]bool, len(additionalKeys))
+	for _, k := range additionalKeys {
+		explicit[k] = true
+	}
+	credSet := make(map[string]bool)
+	for _, k := range SplitCSV(viper.GetString(keys.CredentialsList)) {
+		credSet[k] = true
+	}
+
+	sort.Strings(additionalKeys)
+	w := sectionWriter{explicit: explicit, credentials: credSet, written: map[string]map[string]string{}}
+	if name := CredentialSection(envName); name != envName {
+		// The environment section keeps the credentials of the default
+		// identity, which Viper does not hold.
+		credSec := cfg.Section(name)
+		envCredentials := sectionCredentials(sec)
+		owned := func(key string) bool {
+			return isIdentityKey(key, credSet) || envCredentials[key]
+		}
+		if err := w.write(sec, additionalKeys, func(key string) bool { return !owned(key) }); err != nil {
+			return err
+		}
+		if err := w.write(credSec, additionalKeys, func(key string) bool {
+			return owned(key) || credSec.HasKey(key)
+		}); err != nil {
+			return err
+		}
+	} else if err := w.write(sec, additionalKeys, func(string) bool { return true }); err != nil {
+		return err
+	}
+
+	if !cfg.Section("DEFAULT").HasKey(keys.CurrentEnvironment) {
+		cfg.Section("DEFAULT").Key(keys.CurrentEnvironment).SetValue(envName)
+	}
+	if err := saveIniAtomic(cfg, iniPath); err != nil {
+		return err
+	}
+	for name, values := range w.written {
+		for k, v := range values {
+			loadedSections[name][k] = v
+		}
+	}
+	return nil
+}
+
+// sectionWriter writes Viper values to INI sections for PersistToIni.
+type sectionWriter struct {
+	explicit    map[string]bool
+	credentials map[string]bool
+	// written holds the values set in the sections loaded into Viper; the
+	// keys left to another process keep the value this process loaded.
+	written map[string]map[string]string
+}
+
+// write updates the keys of sec accepted by include: the existing ones not
+// changed by another process, then the additional ones.
+func (w *sectionWriter) write(sec *ini.Section, additionalKeys []string, include func(string) bool) error {
+	loaded := loadedSections[sec.Name()]
+	if loaded != nil && w.written[sec.Name()] == nil {
+		w.written[sec.Name()] = map[string]string{}
+	}
+	iniValue := func(name string, current string) (string, error) {
+		value := viper.GetString(name)
+		if loaded != nil {
+			w.written[sec.Name()][name] = value
+		}
+		if !w.credentials[name] {
+			return value, nil
+		}
+		return secretIniValue(sec.Name(), name, value, current)
+	}
+
+	// Update all existing section keys from Viper, except the plain proxy
+	// names, which Viper does not hold.
 	for _, k := range sec.Keys() {
 		name := k.Name()
-		if InternalKeys[name] {
+		if InternalKeys[name] || proxyKeyAliases[name] != "" || !include(name) {
 			continue
 		}
-		k.SetValue(viper.GetString(name))
+		if loaded != nil && !w.explicit[name] {
+			old, ok := loaded[name]
+			if !ok {
+				continue // added by another process
+			}
+			if current, err := ResolveSecretRef(k.Value()); err != nil || current != old {
+				continue // changed by another process
+			}
+		}
+		v, err := iniValue(name, k.Value())
+		if err != nil {
+			return err
+		}
+		k.SetValue(v)
 	}
 
 	// Upsert explicitly-provided additional keys in sorted order.
-	sort.Strings(additionalKeys)
 	for _, name := range additionalKeys {
-		if InternalKeys[name] {
+		if InternalKeys[name] || !include(name) {
 			continue
 		}
 		if sec.HasKey(name) {
-			sec.Key(name).SetValue(viper.GetString(name))
+			v, err := iniValue(name, sec.Key(name).Value())
+			if err != nil {
+				return err
+			}
+			sec.Key(name).SetValue(v)
 		} else {
-			sec.NewKey(name, viper.GetString(name))
+			v, err := iniValue(name, "")
+			if err != nil {
+				return err
+			}
+			sec.NewKey(name, v)
 		}
 	}
+	return nil
+}
 
-	if !cfg.Section("DEFAULT").HasKey(keys.CurrentEnvironment) {
-		cfg.Section("DEFAULT").Key(keys.CurrentEnvironment).SetValue(envName)
+// rememberSection records the values of a section loaded into Viper.
+func rememberSection(sec *ini.Section) {
+	values := make(map[string]string)
+	for _, k := range sec.Keys() {
+		v, err := ResolveSecretRef(k.Value())
+		if err != nil {
+			v = k.Value()
+		}
+		values[k.Name()] = v
+	}
+	loadedSections[sec.Name()] = values
+}
+
+// adoptPersistedCredentials reloads the credentials of the current
+// environment when another process renewed them after they were loaded. It
+// reports whether the adopted access token is fresh enough to be used as is.
+// The caller holds the INI lock, so secrets are read again from their store.
+func adoptPersistedCredentials() bool {
+	name := CredentialSection(viper.GetString(keys.CurrentEnvironment))
+	loaded := loadedSections[name]
+	if loaded == nil {
+		return false
+	}
+	cfg, err := ini.Load(GetIniPath())
+	if err != nil || !cfg.HasSection(name) {
+		return false
+	}
+	sec := cfg.Section(name)
+	if !sec.HasKey(keys.DhCoreAccessToken) {
+		return false
+	}
+	token, err := ResolveSecretRef(sec.Key(keys.DhCoreAccessToken).Value())
+	if err != nil || token == loaded[keys.DhCoreAccessToken] {
+		return false
+	}
+
+	names := []string{keys.CredentialsList}
+	if sec.HasKey(keys.CredentialsList) {
+		names = append(names, SplitCSV(sec.Key(keys.CredentialsList).Value())...)
 	}
-	return cfg.SaveTo(iniPath)
+	for _, key := range names {
+		if !sec.HasKey(key) {
+			continue
+		}
+		raw := sec.Key(key).Value()
+		v, err := ResolveSecretRef(raw)
+		if err != nil {
+			return false
+		}
+		viper.Set(key, v)
+		loaded[key] = v
+	}
+
+	if expiresAt, err := time.Parse(time.RFC3339, viper.GetString(keys.DhCoreExpiresAt)); err == nil {
+		return time.Until(expiresAt) >= 5*time.Minute
+	}
+	return true
 }
 
 // PersistCurrentEnv is a convenience wrapper around PersistToIni that resolves
@@ -78,6 +249,59 @@ func PersistCurrentEnv(additionalKeys []string) error {
 	return PersistToIni(GetIniPath(), env, additionalKeys)
 }
 
+// DeleteIniKeys removes keys from the named INI section, together with the
+// secrets they reference, and drops them from its credentials_list. The
+// credentials of the active identity are in the section named by
+// CredentialSection.
+func DeleteIniKeys(iniPath, envName string, names []string) error {
+	unlock, err := LockIni()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg, err := loadIniForUpdate(iniPath)
+	if err != nil {
+		return err
+	}
+	if !cfg.HasSection(envName) {
+		return fmt.Errorf("environment %s not found", envName)
+	}
+	sec := cfg.Section(envName)
+
+	drop := make(map[string]bool, len(names))
+	for _, name := range names {
+		drop[name] = true
+		if !sec.HasKey(name) {
+			continue
+		}
+		if storeName, account, ok := secrets.ParseRef(sec.Key(name).Value()); ok {
+			deleteSecret(storeName, account)
+		}
+		sec.DeleteKey(name)
+	}
+	if sec.HasKey(keys.CredentialsList) {
+		var kept []string
+		for _, k := range SplitCSV(sec.Key(keys.CredentialsList).Value()) {
+			if !drop[k] {
+				kept = append(kept, k)
+			}
+		}
+		sec.Key(keys.CredentialsList).SetValue(strings.Join(kept, ","))
+		if envName == CredentialSection(viper.GetString(keys.CurrentEnvironment)) {
+			viper.Set(keys.CredentialsList, strings.Join(kept, ","))
+		}
+	}
+
+	if err := saveIniAtomic(cfg, iniPath); err != nil {
+		return err
+	}
+	if _, ok := loadedSections[envName]; ok {
+		rememberSection(sec)
+	}
+	return nil
+}
+
 // resolveEnvName: --env > "default"
 func resolveEnvName(optionalEnv ...string) string {
 	if len(optionalEnv) > 0 && optionalEnv[0] != "" && strings.ToLower(optionalEnv[0]) != "null" {
@@ -103,10 +327,30 @@ func loadIniSectionIntoViper(cfg *ini.File, env string) error {
 	for _, k := range def.Keys() {
 		merged[k.Name()] = k.Value()
 	}
+	loadedSections = map[string]map[string]string{}
+	activeIdentity, identityEnv = "", ""
 	if selected != nil && selected != def {
 		for _, k := range selected.Keys() {
 			merged[k.Name()] = k.Value()
 		}
+		if err := loadIdentity(cfg, selected, merged); err != nil {
+			return err
+		}
+	}
+	rememberSection(selected)
+	applyProxyAliases(merged)
+
+	// Credentials kept in a secret store are referenced from the INI.
+	var secretErr error
+	for k, v := range merged {
+		resolved, err := ResolveSecretRef(v)
+		if err != nil && secretErr == nil {
+			secretErr = err
+		}
+		merged[k] = resolved
+	}
+	if secretErr != nil {
+		logger.Error(fmt.Sprintf("Cannot load credentials from the secret store: %v", secretErr))
 	}
 
 	var buf bytes.Buffer
@@ -118,6 +362,42 @@ func loadIniSectionIntoViper(cfg *ini.File, env string) error {
 	return viper.ReadConfig(&buf)
 }
 
+// loadIdentity replaces in merged the credentials of the environment section
+// with the ones of the identity selected with --identity or recorded by
+// `dhcli use --as`, if not the default one.
+func loadIdentity(cfg *ini.File, sec *ini.Section, merged map[string]string) error {
+	identity := selectedIdentity
+	if identity == "" {
+		identity = RecordedIdentity(sec)
+	}
+	if identity == DefaultIdentity {
+		return nil
+	}
+	if err := CheckIdentityName(identity); err != nil {
+		return err
+	}
+	activeIdentity, identityEnv = identity, sec.Name()
+	logger.Info(fmt.Sprintf("Using identity: %s", identity))
+
+	envCredentials := sectionCredentials(sec)
+	for k := range merged {
+		if isIdentityKey(k, envCredentials) {
+			delete(merged, k)
+		}
+	}
+	name := IdentitySection(sec.Name(), identity)
+	if !cfg.HasSection(name) {
+		logger.Warn(fmt.Sprintf("No credentials for identity '%s' yet: log in with --as %s", identity, identity))
+		return nil
+	}
+	idSec := cfg.Section(name)
+	for _, k := range idSec.Keys() {
+		merged[k.Name()] = k.Value()
+	}
+	rememberSection(idSec)
+	return nil
+}
+
 // RegisterIniCfgWithViper:
 // 1) bind ENV from struct (live)
 // 2) load INI or lazy-bootstraps it from well-known (writes only target env)
@@ -173,6 +453,11 @@ func bootstrapFromEnv(iniPath string, optionalEnv ...string) (string, error) {
 
 	var additionalKeys []string
 
+	// TLS and proxy settings can come from the environment too
+	if err := ConfigureTransportFromConfig(); err != nil {
+		return "", err
+	}
+
 	cfg, err := FetchConfig(baseEndpoint + "/.well-known/configuration")
 	if err != nil {
 		return "", fmt.Errorf("fetching configuration failed: %w", err)
@@ -199,6 +484,9 @@ func bootstrapFromEnv(iniPath string, optionalEnv ...string) (string, error) {
 			envName = nm
 		}
 	}
+	if err := CheckEnvName(envName); err != nil {
+		return "", err
+	}
 	viper.Set(keys.CurrentEnvironment, envName)
 
 	ts := time.Now().UTC().Format(time.RFC3339)
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dhcli/keys"
+
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// Workspace is the content of a .dhcli.yaml file, which pins the settings of
+// a directory tree.
+type Workspace struct {
+	Environment string                 `json:"environment,omitempty"`
+	Project     string                 `json:"project,omitempty"`
+	Output      string                 `json:"output,omitempty"`
+	Run         map[string]interface{} `json:"run,omitempty"`
+
+	// Path is the file the workspace was read from.
+	Path string `json:"-"`
+}
+
+var currentWorkspace *Workspace
+
+// FindWorkspace looks for .dhcli.yaml in the working directory and its
+// parents. It returns nil when there is none.
+func FindWorkspace() (*Workspace, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, keys.WorkspaceFile)
+		if _, err := os.Stat(path); err == nil {
+			return ReadWorkspace(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// ReadWorkspace parses a workspace file.
+func ReadWorkspace(path string) (*Workspace, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ws Workspace
+	if err := yaml.UnmarshalStrict(b, &ws); err != nil {
+		return nil, fmt.Errorf("invalid workspace file %s: %w", path, err)
+	}
+	ws.Path = path
+	return &ws, nil
+}
+
+// WriteWorkspace saves a workspace file, refusing to replace an existing one
+// unless force is set.
+func WriteWorkspace(ws *Workspace, force bool) error {
+	if !force {
+		if _, err := os.Stat(ws.Path); err == nil {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", ws.Path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	b, err := yaml.Marshal(ws)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ws.Path, b, 0o644)
+}
+
+// LoadWorkspace finds the workspace of the working directory and remembers
+// it for ApplyWorkspace. A broken file is an error rather than being skipped,
+// as it may pin a different environment.
+func LoadWorkspace() (*Workspace, error) {
+	ws, err := FindWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		logger.Info(fmt.Sprintf("Using workspace %s", ws.Path))
+	}
+	currentWorkspace = ws
+	return ws, nil
+}
+
+// CurrentWorkspace returns the workspace loaded for this command, if any.
+func CurrentWorkspace() *Workspace {
+	return currentWorkspace
+}
+
+// ApplyWorkspace merges the loaded workspace into Viper on top of the INI
+// configuration, so flags and environment variables (PROJECT_NAME,
+// DHCLI_OUTPUT) still take precedence. Run defaults are read from
+// CurrentWorkspace instead: Viper would lowercase their keys.
+func ApplyWorkspace() error {
+	ws := currentWorkspace
+	if ws == nil {
+		return nil
+	}
+	values := map[string]interface{}{}
+	if ws.Project != "" {
+		values[keys.ProjectName] = ws.Project
+	}
+	if ws.Output != "" {
+		values[keys.OutputFormat] = ws.Output
+	}
+	for k := range values {
+		InternalKeys[k] = true
+	}
+	return viper.MergeConfigMap(values)
+}
+
+// MergeDefaults returns values with the missing entries taken from defaults,
+// descending into nested maps.
+func MergeDefaults(values map[string]interface{}, defaults map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values)+len(defaults))
+	for k, v := range defaults {
+		out[k] = v
+	}
+	for k, v := range values {
+		vm, ok1 := v.(map[string]interface{})
+		dm, ok2 := out[k].(map[string]interface{})
+		if ok1 && ok2 {
+			out[k] = MergeDefaults(vm, dm)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
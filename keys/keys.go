@@ -9,6 +9,7 @@ const (
 	IniName            = ".dhcore.ini"
 	IniSource          = "ini_source"
 	CurrentEnvironment = "current_environment"
+	CurrentIdentity    = "current_identity"
 	UpdatedEnvKey      = "updated_environment"
 	ApiLevelKey        = "dhcore_api_level"
 
@@ -25,9 +26,30 @@ const (
 	DhCorePassword              = "dhcore_password"
 	DhCoreRefreshToken          = "dhcore_refresh_token"
 	DhCoreProxy                 = "dhcore_proxy"
+	DhCoreGrantType             = "dhcore_grant_type"
+	DhCoreServiceClientId       = "dhcore_service_client_id"
+	DhCoreServiceSecretFile     = "dhcore_service_client_secret_file"
+	DhCoreServiceSecret         = "dhcore_service_client_secret"
 	OAuth2TokenEndpoint         = "oauth2_token_endpoint"
 	OAuth2AuthorizationEndpoint = "oauth2_authorization_endpoint"
+	OAuth2DeviceEndpoint        = "oauth2_device_authorization_endpoint"
 	OAuth2ScopesSupported       = "oauth2_scopes_supported"
+	OAuth2RevocationEndpoint    = "oauth2_revocation_endpoint"
+	OAuth2EndSessionEndpoint    = "oauth2_end_session_endpoint"
+	OAuth2UserinfoEndpoint      = "oauth2_userinfo_endpoint"
+	OAuth2IntrospectionEndpoint = "oauth2_introspection_endpoint"
+	CacheMaxSize                = "cache_max_size"
+	SecretStore                 = "secret_store"
+	SecretStoreFile             = "secret_store_file"
+	ProjectName                 = "project_name"
+	OutputFormat                = "dhcli_output"
+	WorkspaceFile               = ".dhcli.yaml"
+	TLSCAFile                   = "tls_ca_file"
+	TLSClientCert               = "tls_client_cert"
+	TLSClientKey                = "tls_client_key"
+	TLSInsecureSkipVerify       = "tls_insecure_skip_verify"
+	HTTPProxy                   = "dhcore_http_proxy"
+	NoProxy                     = "dhcore_no_proxy"
 
 	// API level the current version of the CLI was developed for
 	MinApiLevel = 10
@@ -66,3 +88,54 @@ var Resources = map[string][]string{
 	"workflows": {"workflow"},
 	"logs":      {"log"},
 }
+
+// ConfigKeys lists the environment settings known to the CLI, which can be
+// changed with `config set`.
+var ConfigKeys = map[string]bool{
+	DhCoreName:                  true,
+	DhCoreIssuer:                true,
+	DhCoreClientId:              true,
+	DhCoreEndpoint:              true,
+	DhCoreApiVersion:            true,
+	ApiLevelKey:                 true,
+	DhCoreProxy:                 true,
+	DhCoreGrantType:             true,
+	DhCoreServiceClientId:       true,
+	DhCoreServiceSecretFile:     true,
+	OAuth2TokenEndpoint:         true,
+	OAuth2AuthorizationEndpoint: true,
+	OAuth2DeviceEndpoint:        true,
+	OAuth2ScopesSupported:       true,
+	OAuth2RevocationEndpoint:    true,
+	OAuth2EndSessionEndpoint:    true,
+	OAuth2UserinfoEndpoint:      true,
+	OAuth2IntrospectionEndpoint: true,
+	CacheMaxSize:                true,
+	SecretStore:                 true,
+	SecretStoreFile:             true,
+	TLSCAFile:                   true,
+	TLSClientCert:               true,
+	TLSClientKey:                true,
+	TLSInsecureSkipVerify:       true,
+	HTTPProxy:                   true,
+	NoProxy:                     true,
+	UpdatedEnvKey:               true,
+	IniSource:                   true,
+	"aws_region":                true,
+	"aws_endpoint_url":          true,
+}
+
+// CredentialKeys lists the known keys holding secrets; they are kept in
+// credentials_list.
+var CredentialKeys = map[string]bool{
+	DhCoreAccessToken:            true,
+	DhCoreRefreshToken:           true,
+	DhCoreIdToken:                true,
+	DhCoreExpiresAt:              true,
+	DhCoreUser:                   true,
+	DhCorePassword:               true,
+	"aws_access_key_id":          true,
+	"aws_secret_access_key":      true,
+	"aws_session_token":          true,
+	"aws_credentials_expiration": true,
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"dhcli/handlers/agent"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Serve fresh credentials to local processes",
+	Long: `The agent is a background process serving the access token and S3 credentials of an environment
+to local tools, e.g. SDK processes started from notebooks, and refreshing them centrally instead of
+each process racing on the INI file.
+
+It listens on a Unix socket readable by the current user only and, with --http, on a localhost port
+requiring the session secret as bearer token:
+
+  GET  /v1/token     access token, its expiry and the endpoint
+  GET  /v1/s3        S3 credentials
+  GET  /v1/status    agent state
+  POST /v1/shutdown  stop the agent`,
+}
+
+var agentStartCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	foregroundFlag := flags.NewBoolFlag("foreground", "", "serve in the current process", false)
+	httpFlag := flags.NewBoolFlag("http", "", "serve on a localhost port too, guarded by a per-session secret", false)
+	httpPortFlag := flags.NewIntFlag("http-port", "", "port of the HTTP endpoint (default: any free port)", 0)
+
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the agent for the current environment and identity",
+		Long:  `Start the agent and print the variables locating it (DHCLI_AGENT_SOCKET, DHCLI_AGENT_URL and DHCLI_AGENT_SECRET), to be loaded with: eval "$(dhcli agent start)"`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := agent.Options{
+				Foreground: *foregroundFlag.Value,
+				HTTP:       *httpFlag.Value,
+				HTTPPort:   *httpPortFlag.Value,
+			}
+			if err := agent.StartHandler(opts); err != nil {
+				log.Fatalf("Agent start failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &foregroundFlag)
+	flags.AddFlag(cmd, &httpFlag)
+	flags.AddFlag(cmd, &httpPortFlag)
+
+	return cmd
+}()
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of the agent",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := agent.StatusHandler(); err != nil {
+			log.Fatalf("Agent status failed: %v", err)
+		}
+	},
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the agent",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := agent.StopHandler(); err != nil {
+			log.Fatalf("Agent stop failed: %v", err)
+		}
+	},
+}
+
+func init() {
+	agentCmd.AddCommand(agentStartCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentStopCmd)
+	pkg.RegisterCommand(agentCmd)
+}
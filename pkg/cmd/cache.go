@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"dhcli/handlers/cache"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local download cache",
+	Long:  "Manage the local download cache, stored under the user cache directory (override with DHCLI_CACHE_DIR).",
+}
+
+var cacheLsCmd = func() *cobra.Command {
+	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "")
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List cached objects",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cache.ListHandler(*outFlag.Value); err != nil {
+				log.Fatalf("Cache list failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &outFlag)
+
+	return cmd
+}()
+
+var cachePruneCmd = func() *cobra.Command {
+	maxSizeFlag := flags.NewStringFlag("max-size", "s", "evict least recently used objects until the cache fits this size (e.g. 500MB, 10GiB)", "")
+	olderThanFlag := flags.NewStringFlag("older-than", "", "evict objects not used within this duration (e.g. 720h)", "")
+	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "")
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict cached objects by size and/or age",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cache.PruneHandler(*maxSizeFlag.Value, *olderThanFlag.Value, *outFlag.Value); err != nil {
+				log.Fatalf("Cache prune failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &maxSizeFlag)
+	flags.AddFlag(cmd, &olderThanFlag)
+	flags.AddFlag(cmd, &outFlag)
+
+	return cmd
+}()
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached object",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cache.ClearHandler(); err != nil {
+			log.Fatalf("Cache clear failed: %v", err)
+		}
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	pkg.RegisterCommand(cacheCmd)
+}
@@ -38,6 +38,74 @@ var configCmd = func() *cobra.Command {
 	return cmd
 }()
 
+var configSetCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	credentialFlag := flags.NewBoolFlag("credential", "", "the key holds a secret: store it as a credential", false)
+	forceFlag := flags.NewBoolFlag("force", "", "set a key unknown to dhcli", false)
+
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a value in the environment configuration",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.ConfigSetHandler(args[0], args[1], *credentialFlag.Value, *forceFlag.Value); err != nil {
+				log.Fatalf("Config set failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &credentialFlag)
+	flags.AddFlag(cmd, &forceFlag)
+
+	return cmd
+}()
+
+var configUnsetCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	credentialFlag := flags.NewBoolFlag("credential", "", "allow removing a credential", false)
+
+	cmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a value from the environment configuration",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.ConfigUnsetHandler(args[0], *credentialFlag.Value); err != nil {
+				log.Fatalf("Config unset failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &credentialFlag)
+
+	return cmd
+}()
+
+var configGetCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	credentialFlag := flags.NewBoolFlag("credential", "", "allow printing a credential", false)
+
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a value of the environment configuration",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.ConfigGetHandler(args[0], *credentialFlag.Value); err != nil {
+				log.Fatalf("Config get failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &credentialFlag)
+
+	return cmd
+}()
+
 func init() {
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configGetCmd)
 	pkg.RegisterCommand(configCmd)
 }
@@ -6,8 +6,10 @@ package cmd
 
 import (
 	"log"
+	"strings"
 
 	"dhcli/handlers/config"
+	"dhcli/handlers/utils"
 	"dhcli/pkg"
 	"dhcli/pkg/flags"
 
@@ -18,13 +20,19 @@ var credentialsCmd = func() *cobra.Command {
 	envFlag := flags.NewStringFlag("env", "e", "environment", "")
 	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "")
 	providerFlag := flags.NewStringFlag("provider", "p", "filter credentials by provider (e.g. dhcore, trino, s3)", "")
+	formatFlag := flags.NewStringFlag("format", "", "export format ("+strings.Join(config.ExportFormats, ", ")+")", "")
 
 	cmd := &cobra.Command{
 		Use:   "credentials",
 		Short: "Print current environment credentials (secret values)",
 		Run: func(cmd *cobra.Command, args []string) {
 			_ = envFlag // env is handled by PersistentPreRunE
-			err := config.CredentialsHandler(*outFlag.Value, *providerFlag.Value)
+			var err error
+			if *formatFlag.Value != "" {
+				err = config.CredentialsExportHandler(*formatFlag.Value, *providerFlag.Value)
+			} else {
+				err = config.CredentialsHandler(*outFlag.Value, *providerFlag.Value)
+			}
 			if err != nil {
 				log.Fatalf("Credentials failed: %v", err)
 			}
@@ -34,10 +42,53 @@ var credentialsCmd = func() *cobra.Command {
 	flags.AddFlag(cmd, &envFlag)
 	flags.AddFlag(cmd, &outFlag)
 	flags.AddFlag(cmd, &providerFlag)
+	flags.AddFlag(cmd, &formatFlag)
+	cmd.MarkFlagsMutuallyExclusive("out", "format")
+
+	return cmd
+}()
+
+var credentialsStoreCmd = &cobra.Command{
+	Use:   "store <plaintext|keyring|file>",
+	Short: "Choose where credentials are stored",
+	Long: "Move the credentials of every environment to the given secret store; the INI file then only holds references.\n" +
+		"keyring uses the OS keyring (Secret Service on Linux, Keychain on macOS); file uses a passphrase-encrypted file\n" +
+		"next to the INI file (passphrase from " + utils.SecretPassphraseEnv + " or prompted); plaintext restores the previous behaviour.",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"plaintext", "keyring", "file"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.SecretStoreHandler(args[0]); err != nil {
+			log.Fatalf("Credentials store failed: %v", err)
+		}
+	},
+}
+
+var credentialsWriteAwsProfileCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	processFlag := flags.NewBoolFlag("process", "", "configure dhcli as credential_process instead of copying the keys", false)
+
+	cmd := &cobra.Command{
+		Use:   "write-aws-profile <name>",
+		Short: "Write the S3 credentials to an AWS profile",
+		Long: `Upsert the named profile in ~/.aws/credentials and ~/.aws/config (or AWS_SHARED_CREDENTIALS_FILE
+and AWS_CONFIG_FILE) with the S3 credentials, region and endpoint of the environment.
+Copied session credentials expire: with --process the profile calls dhcli to get fresh ones instead.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.WriteAwsProfileHandler(args[0], *processFlag.Value); err != nil {
+				log.Fatalf("Write AWS profile failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &processFlag)
 
 	return cmd
 }()
 
 func init() {
+	credentialsCmd.AddCommand(credentialsStoreCmd)
+	credentialsCmd.AddCommand(credentialsWriteAwsProfileCmd)
 	pkg.RegisterCommand(credentialsCmd)
 }
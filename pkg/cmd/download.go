@@ -25,11 +25,13 @@ var downloadCmd = func() *cobra.Command {
 	destinationFlag := flags.NewStringFlag("destination", "d", "output filename or directory", "")
 	outFlag := flags.NewStringFlag("out", "o", "Output format (short, json, yaml)", "")
 	verboseFlag := flags.NewBoolFlag("verbose", "v", "Verbose progress/logging", false)
+	noCacheFlag := flags.NewBoolFlag("no-cache", "", "Bypass the local download cache", false)
+	extractFlag := flags.NewBoolFlag("extract", "", "Unpack zip and tar.gz archives after download", false)
 
 	cmd := &cobra.Command{
 		Use:   "download <resource> [<id>]",
 		Short: "Download a resource from the S3 aws",
-		Long:  "Download a resource from S3 aws",
+		Long:  "Download a resource from S3 aws. S3 objects are served from the local cache when their ETag is unchanged; use --no-cache to bypass it.",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 || len(args) > 2 {
 				return errors.New("requires 1 or 2 arguments: <resource> [<id>]")
@@ -52,6 +54,8 @@ var downloadCmd = func() *cobra.Command {
 				args[0],
 				id,
 				*verboseFlag.Value,
+				*noCacheFlag.Value,
+				*extractFlag.Value,
 			); err != nil {
 				log.Fatalf("Download failed: %v", err)
 			}
@@ -65,6 +69,8 @@ var downloadCmd = func() *cobra.Command {
 	flags.AddFlag(cmd, &outFlag)
 	flags.AddFlag(cmd, &destinationFlag)
 	flags.AddFlag(cmd, &verboseFlag)
+	flags.AddFlag(cmd, &noCacheFlag)
+	flags.AddFlag(cmd, &extractFlag)
 
 	return cmd
 }()
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"dhcli/handlers/environment"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Share, copy and rename environments",
+	Long: `An environment definition is the configuration of an environment without its credentials,
+as YAML. It can be exported, handed to a colleague and imported on another machine.`,
+}
+
+var envExportCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [<environment>]",
+		Short: "Print the definition of an environment (default: current one)",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			env := ""
+			if len(args) > 0 {
+				env = args[0]
+			}
+			if err := environment.EnvExportHandler(env); err != nil {
+				log.Fatalf("Export failed: %v", err)
+			}
+		},
+	}
+
+	return cmd
+}()
+
+var envImportCmd = func() *cobra.Command {
+	asFlag := flags.NewStringFlag("as", "", "name of the imported environment (default: name in the file)", "")
+	refreshFlag := flags.NewBoolFlag("refresh", "", "fetch the well-known configuration of the endpoint again", false)
+	forceFlag := flags.NewBoolFlag("force", "f", "override an existing environment", false)
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Create an environment from a definition file ('-' for stdin)",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := environment.EnvImportHandler(args[0], *asFlag.Value, *refreshFlag.Value, *forceFlag.Value); err != nil {
+				log.Fatalf("Import failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &asFlag)
+	flags.AddFlag(cmd, &refreshFlag)
+	flags.AddFlag(cmd, &forceFlag)
+
+	return cmd
+}()
+
+var envCloneCmd = func() *cobra.Command {
+	forceFlag := flags.NewBoolFlag("force", "f", "override an existing environment", false)
+
+	cmd := &cobra.Command{
+		Use:   "clone <source> <destination>",
+		Short: "Copy an environment, without its credentials",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := environment.EnvCloneHandler(args[0], args[1], *forceFlag.Value); err != nil {
+				log.Fatalf("Clone failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &forceFlag)
+
+	return cmd
+}()
+
+var envRenameCmd = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Rename an environment",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := environment.EnvRenameHandler(args[0], args[1]); err != nil {
+				log.Fatalf("Rename failed: %v", err)
+			}
+		},
+	}
+
+	return cmd
+}()
+
+func init() {
+	envCmd.AddCommand(envExportCmd)
+	envCmd.AddCommand(envImportCmd)
+	envCmd.AddCommand(envCloneCmd)
+	envCmd.AddCommand(envRenameCmd)
+	pkg.RegisterCommand(envCmd)
+}
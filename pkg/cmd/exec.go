@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"dhcli/handlers/config"
+	"dhcli/handlers/utils"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var execCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	projectFlag := flags.NewStringFlag("project", "p", "project, exported as PROJECT_NAME", "")
+	refreshFlag := flags.NewBoolFlag("refresh", "", "keep a refreshed access token in DHCORE_ACCESS_TOKEN_FILE while the command runs", false)
+
+	cmd := &cobra.Command{
+		Use:   "exec [flags] -- <command> [args...]",
+		Short: "Run a command with the environment credentials",
+		Long: `Run a command with the DHCORE_*, AWS_* and DB_* variables of the environment and its
+credentials exported, plus PROJECT_NAME when a project is given. Signals are forwarded to the
+command and its exit code is returned.`,
+		Example: "  dhcli exec -p my-project -- python train.py\n  dhcli exec -- aws s3 ls",
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			code, err := config.ExecHandler(utils.ResolveProject(*projectFlag.Value), *refreshFlag.Value, args)
+			if err != nil {
+				log.Printf("Exec failed: %v", err)
+			}
+			os.Exit(code)
+		},
+	}
+	// everything after the command name belongs to the command
+	cmd.Flags().SetInterspersed(false)
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &projectFlag)
+	flags.AddFlag(cmd, &refreshFlag)
+
+	return cmd
+}()
+
+func init() {
+	pkg.RegisterCommand(execCmd)
+}
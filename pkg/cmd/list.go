@@ -19,7 +19,7 @@ import (
 var listCmd = func() *cobra.Command {
 	// Declare local flags using generic constructors
 	envFlag := flags.NewStringFlag("env", "e", "environment", "")
-	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "short")
+	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "")
 	projectFlag := flags.NewStringFlag("project", "p", "Mandatory for resources other than projects", "")
 	nameFlag := flags.NewStringFlag("name", "n", "If specified, all versions of the resource will be listed", "")
 
@@ -9,22 +9,63 @@ import (
 	"os"
 
 	"dhcli/handlers/auth"
+	"dhcli/handlers/utils"
+	"dhcli/keys"
 	"dhcli/pkg"
 	"dhcli/pkg/flags"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var loginCmd = func() *cobra.Command {
 	// Declare local flags
 	envFlag := flags.NewStringFlag("env", "e", "environment", "")
 	patFlag := flags.NewStringFlag("pat", "", "personal access token (non-interactive flow)", "")
+	deviceFlag := flags.NewBoolFlag("device", "", "use the device authorization flow (no local browser needed)", false)
+	clientCredentialsFlag := flags.NewBoolFlag("client-credentials", "", "log in as a confidential client (service account)", false)
+	clientIdFlag := flags.NewStringFlag("client-id", "", "client id for --client-credentials (or env DHCORE_SERVICE_CLIENT_ID)", "")
+	clientSecretFileFlag := flags.NewStringFlag("client-secret-file", "", "file holding the client secret for --client-credentials (or env DHCORE_SERVICE_CLIENT_SECRET_FILE / DHCORE_SERVICE_CLIENT_SECRET)", "")
+	asFlag := flags.NewStringFlag("as", "", "named identity to log in as, keeping its own tokens (see 'dhcli use --as')", "")
 
 	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Log in to a given environment",
-		Long:  "Authenticate the user using OAuth2 PKCE flow with the specified environment. Use --pat (or env DHCORE_PERSONAL_ACCESS_TOKEN / DHCORE_PAT) for non-interactive token exchange.",
+		Long:  "Authenticate the user using OAuth2 PKCE flow with the specified environment. Use --device on machines without a browser (SSH hosts, remote containers), --client-credentials for service accounts, or --pat (or env DHCORE_PERSONAL_ACCESS_TOKEN / DHCORE_PAT) for non-interactive token exchange; --device and --client-credentials take precedence over a token in the environment. Use --as to log in as another identity of the environment, e.g. an administrator account, without replacing the tokens of the default one.",
 		Run: func(cmd *cobra.Command, args []string) {
+			if *asFlag.Value != "" {
+				if err := utils.SelectIdentity(*asFlag.Value); err != nil {
+					log.Fatalf("Login failed: %v", err)
+				}
+				// reload the environment with the credentials of the identity
+				if err := utils.RegisterIniCfgWithViper(viper.GetString(keys.CurrentEnvironment)); err != nil {
+					log.Fatalf("Failed to load configuration: %v", err)
+				}
+			}
+
+			if *clientCredentialsFlag.Value {
+				clientID := *clientIdFlag.Value
+				if clientID == "" {
+					clientID = os.Getenv("DHCORE_SERVICE_CLIENT_ID")
+				}
+				secretFile := *clientSecretFileFlag.Value
+				if secretFile == "" {
+					secretFile = os.Getenv("DHCORE_SERVICE_CLIENT_SECRET_FILE")
+				}
+				if err := auth.ClientCredentialsLoginHandler(clientID, secretFile); err != nil {
+					log.Fatalf("Login failed: %v", err)
+				}
+				return
+			}
+
+			// an explicit --device wins over a token in the environment
+			if *deviceFlag.Value {
+				if err := auth.DeviceLoginHandler(); err != nil {
+					log.Fatalf("Login failed: %v", err)
+				}
+				return
+			}
+
 			pat := *patFlag.Value
 			if pat == "" {
 				pat = os.Getenv("DHCORE_PERSONAL_ACCESS_TOKEN")
@@ -32,7 +73,6 @@ var loginCmd = func() *cobra.Command {
 			if pat == "" {
 				pat = os.Getenv("DHCORE_PAT")
 			}
-
 			if pat != "" {
 				if err := auth.PatLoginHandler(pat); err != nil {
 					log.Fatalf("Login failed: %v", err)
@@ -49,6 +89,12 @@ var loginCmd = func() *cobra.Command {
 	// Add local flags
 	flags.AddFlag(cmd, &envFlag)
 	flags.AddFlag(cmd, &patFlag)
+	flags.AddFlag(cmd, &deviceFlag)
+	flags.AddFlag(cmd, &clientCredentialsFlag)
+	flags.AddFlag(cmd, &clientIdFlag)
+	flags.AddFlag(cmd, &clientSecretFileFlag)
+	flags.AddFlag(cmd, &asFlag)
+	cmd.MarkFlagsMutuallyExclusive("pat", "device", "client-credentials")
 
 	return cmd
 }()
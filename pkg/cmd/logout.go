@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"dhcli/handlers/auth"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var logoutCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	allFlag := flags.NewBoolFlag("all", "", "log out of every environment", false)
+	endSessionFlag := flags.NewBoolFlag("end-session", "", "also end the session at the identity provider", false)
+
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Log out and remove stored credentials",
+		Long: `Log out of an environment: the tokens are revoked when the provider supports it
+and every credential listed in credentials_list is removed from the configuration.
+Use --all to log out of every environment.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := auth.LogoutHandler(*allFlag.Value, *endSessionFlag.Value); err != nil {
+				log.Fatalf("Logout failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &allFlag)
+	flags.AddFlag(cmd, &endSessionFlag)
+
+	return cmd
+}()
+
+func init() {
+	pkg.RegisterCommand(logoutCmd)
+}
@@ -29,13 +29,53 @@ var portForwardCmd = func() *cobra.Command {
 	localPortFlag := flags.NewStringFlag("local-port", "l", "Local port for listening (default: random)", "")
 	functionFlag := flags.NewStringFlag("function", "f", "Function name; if provided, the most recent RUNNING run for that function is used instead of a run ID", "")
 	nameFlag := flags.NewStringFlag("name", "n", "Run name; if provided, the most recent RUNNING run with that name is used instead of a run ID", "")
+	manifestFlag := flags.NewStringFlag("manifest", "m", "YAML file listing several services to forward at once", "")
+	forwardFlag := flags.NewStringArrayFlag("forward", "", "service to forward, as fn=<function>, name=<run name> or run=<run id> with an optional :<local port>; may be repeated", nil)
 
 	cmd := &cobra.Command{
 		Use:   "port-forward [run-id]",
 		Short: "Start local port-forward for a specific run",
-		Long:  "Starts a local port-forward that tunnels requests to the service URL resolved from the run resource, through the configured remote proxy with Authorization",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Starts a local port-forward that tunnels requests to the service URL resolved from the run resource, through the configured remote proxy with Authorization.
+
+Several services can be forwarded from one process with --forward, repeated, or with --manifest (-m; -f is already --function):
+
+  project: my-project        # default project of the forwards
+  forwards:
+    - function: llm          # or name: <run name>, or run: <run id>
+      port: 8081             # local port, random when omitted
+    - name: db
+      port: 8082
+      project: other-project`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			if *manifestFlag.Value != "" || len(*forwardFlag.Value) > 0 {
+				if len(args) > 0 || *functionFlag.Value != "" || *nameFlag.Value != "" || *localPortFlag.Value != "" {
+					log.Fatalf("--manifest and --forward cannot be combined with a run ID, --function, --name or --local-port")
+				}
+				var specs []proxy.ForwardSpec
+				if *manifestFlag.Value != "" {
+					loaded, err := proxy.LoadForwardManifest(*manifestFlag.Value)
+					if err != nil {
+						log.Fatalf("Port-forward error: %v", err)
+					}
+					specs = loaded
+				}
+				for _, value := range *forwardFlag.Value {
+					spec, err := proxy.ParseForwardSpec(value)
+					if err != nil {
+						log.Fatalf("Port-forward error: %v", err)
+					}
+					specs = append(specs, spec)
+				}
+
+				ctx, cancel := signalContext()
+				defer cancel()
+				if err := proxy.StartPortForwards(ctx, specs, utils.ResolveProject(*projectFlag.Value)); err != nil {
+					log.Fatalf("Port-forward error: %v", err)
+				}
+				return
+			}
+
 			project := utils.ResolveProject(*projectFlag.Value)
 			if project == "" {
 				log.Fatalf("Project flag is mandatory (use --project flag or set PROJECT_NAME env variable)")
@@ -74,20 +114,9 @@ var portForwardCmd = func() *cobra.Command {
 				localPort = port
 			}
 
-			// Create a context that can be cancelled by signals
-			ctx, cancel := context.WithCancel(context.Background())
+			ctx, cancel := signalContext()
 			defer cancel()
 
-			// Handle shutdown signals
-			sigCh := make(chan os.Signal, 1)
-			signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-			go func() {
-				sig := <-sigCh
-				utils.GetGlobalLogger().Info("Received signal: " + sig.String())
-				cancel()
-			}()
-
 			// Start the port-forward
 			if err := proxy.StartPortForward(ctx, project, runID, localPort); err != nil {
 				// Graceful shutdown returns http.ErrServerClosed - this is expected
@@ -103,10 +132,28 @@ var portForwardCmd = func() *cobra.Command {
 	flags.AddFlag(cmd, &localPortFlag)
 	flags.AddFlag(cmd, &functionFlag)
 	flags.AddFlag(cmd, &nameFlag)
+	flags.AddFlag(cmd, &manifestFlag)
+	flags.AddFlag(cmd, &forwardFlag)
 
 	return cmd
 }()
 
+// signalContext returns a context cancelled on SIGINT or SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Handle shutdown signals
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-sigCh
+		utils.GetGlobalLogger().Info("Received signal: " + sig.String())
+		cancel()
+	}()
+	return ctx, cancel
+}
+
 func init() {
 	pkg.RegisterCommand(portForwardCmd)
 }
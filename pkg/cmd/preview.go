@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"log"
+
+	"dhcli/handlers/adapter"
+	"dhcli/handlers/utils"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var previewCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	projectFlag := flags.NewStringFlag("project", "p", "Mandatory for resources other than projects", "")
+	nameFlag := flags.NewStringFlag("name", "n", "Alternative to id, will preview latest version", "")
+	outFlag := flags.NewStringFlag("out", "o", "Output format (short, json, yaml)", "")
+	rowsFlag := flags.NewIntFlag("rows", "r", "Number of rows to show", 10)
+
+	cmd := &cobra.Command{
+		Use:   "preview <resource> [<id>]",
+		Short: "Preview the schema and first rows of a dataitem",
+		Long:  "Read the head of CSV and JSON lines files, or the footer and first pages of Parquet files, through ranged requests and print the schema and the first rows. The schema stored on the entity is compared with the actual one.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return errors.New("requires 1 or 2 arguments: <resource> [<id>]")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			id := ""
+			if len(args) > 1 {
+				id = args[1]
+			}
+
+			project := utils.ResolveProject(*projectFlag.Value)
+			if err := adapter.PreviewHandler(
+				*envFlag.Value,
+				*outFlag.Value,
+				project,
+				*nameFlag.Value,
+				args[0],
+				id,
+				*rowsFlag.Value,
+			); err != nil {
+				log.Fatalf("Preview failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &projectFlag)
+	flags.AddFlag(cmd, &nameFlag)
+	flags.AddFlag(cmd, &outFlag)
+	flags.AddFlag(cmd, &rowsFlag)
+
+	return cmd
+}()
+
+func init() {
+	pkg.RegisterCommand(previewCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 
 	"dhcli/handlers/environment"
+	"dhcli/handlers/utils"
 	"dhcli/pkg"
 	"dhcli/pkg/flags"
 
@@ -16,15 +17,32 @@ import (
 var registerCmd = func() *cobra.Command {
 	envFlag := flags.NewStringFlag("env", "e", "environment", "")
 	forceFlag := flags.NewBoolFlag("force", "f", "override existing environment with different endpoint", false)
+	caFileFlag := flags.NewStringFlag("ca-file", "", "CA bundle to trust for the environment", "")
+	clientCertFlag := flags.NewStringFlag("client-cert", "", "client certificate for mutual TLS", "")
+	clientKeyFlag := flags.NewStringFlag("client-key", "", "key of the client certificate", "")
+	insecureFlag := flags.NewBoolFlag("insecure-skip-verify", "", "do not verify the server certificate", false)
+	httpProxyFlag := flags.NewStringFlag("http-proxy", "", "outbound HTTP proxy URL", "")
+	noProxyFlag := flags.NewStringFlag("no-proxy", "", "comma-separated hosts not to reach through the proxy", "")
 
 	cmd := &cobra.Command{
 		Use:   "register <endpoint>",
 		Short: "Register the configuration of a core instance",
-		Args:  cobra.ExactArgs(1),
+		Long: "Register the configuration of a core instance, optionally with the TLS and proxy settings used to reach it. " +
+			"The proxy settings are stored as dhcore_http_proxy and dhcore_no_proxy, so that the HTTP_PROXY and NO_PROXY variables do not override them; " +
+			"http_proxy and no_proxy keys written by hand in the INI file are accepted too.",
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			endpoint := args[0]
 
-			if err := environment.RegisterHandler(*envFlag.Value, endpoint, *forceFlag.Value); err != nil {
+			transport := utils.TransportSettings{
+				CAFile:             *caFileFlag.Value,
+				ClientCert:         *clientCertFlag.Value,
+				ClientKey:          *clientKeyFlag.Value,
+				InsecureSkipVerify: *insecureFlag.Value,
+				HTTPProxy:          *httpProxyFlag.Value,
+				NoProxy:            *noProxyFlag.Value,
+			}
+			if err := environment.RegisterHandler(*envFlag.Value, endpoint, *forceFlag.Value, transport); err != nil {
 				log.Fatalf("Registration failed: %v", err)
 			}
 		},
@@ -32,6 +50,12 @@ var registerCmd = func() *cobra.Command {
 
 	flags.AddFlag(cmd, &envFlag)
 	flags.AddFlag(cmd, &forceFlag)
+	flags.AddFlag(cmd, &caFileFlag)
+	flags.AddFlag(cmd, &clientCertFlag)
+	flags.AddFlag(cmd, &clientKeyFlag)
+	flags.AddFlag(cmd, &insecureFlag)
+	flags.AddFlag(cmd, &httpProxyFlag)
+	flags.AddFlag(cmd, &noProxyFlag)
 
 	return cmd
 }()
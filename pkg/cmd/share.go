@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"log"
+
+	"dhcli/handlers/adapter"
+	"dhcli/handlers/utils"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var shareCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	projectFlag := flags.NewStringFlag("project", "p", "Mandatory for resources other than projects", "")
+	nameFlag := flags.NewStringFlag("name", "n", "Alternative to id, will share latest version", "")
+	outFlag := flags.NewStringFlag("out", "o", "Output format (short, json, yaml)", "")
+	expiresFlag := flags.NewStringFlag("expires", "", "Validity of the links (max 168h)", "24h")
+	uploadFlag := flags.NewBoolFlag("upload", "", "Also generate presigned PUT URLs", false)
+
+	cmd := &cobra.Command{
+		Use:   "share <resource> [<id>]",
+		Short: "Generate presigned links for the files of a resource",
+		Long:  "Generate presigned S3 URLs for every file of an artifact, dataitem or model, so that they can be shared with users without platform accounts.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return errors.New("requires 1 or 2 arguments: <resource> [<id>]")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			id := ""
+			if len(args) > 1 {
+				id = args[1]
+			}
+
+			project := utils.ResolveProject(*projectFlag.Value)
+			if err := adapter.ShareHandler(
+				*envFlag.Value,
+				*outFlag.Value,
+				project,
+				*nameFlag.Value,
+				args[0],
+				id,
+				*expiresFlag.Value,
+				*uploadFlag.Value,
+			); err != nil {
+				log.Fatalf("Share failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &projectFlag)
+	flags.AddFlag(cmd, &nameFlag)
+	flags.AddFlag(cmd, &outFlag)
+	flags.AddFlag(cmd, &expiresFlag)
+	flags.AddFlag(cmd, &uploadFlag)
+
+	return cmd
+}()
+
+func init() {
+	pkg.RegisterCommand(shareCmd)
+}
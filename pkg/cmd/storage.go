@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"dhcli/handlers/adapter"
+	"dhcli/handlers/utils"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Manage the storage backing project data",
+}
+
+var storageMigrateCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	projectFlag := flags.NewStringFlag("project", "p", "project; mandatory", "")
+	toFlag := flags.NewStringFlag("to", "", "destination location (s3://bucket[/prefix]); mandatory", "")
+	fromFlag := flags.NewStringFlag("from", "", "only migrate paths under this location (s3://bucket[/prefix])", "")
+	toEndpointFlag := flags.NewStringFlag("to-endpoint", "", "endpoint of another S3-compatible store; credentials come from the AWS environment", "")
+	toRegionFlag := flags.NewStringFlag("to-region", "", "region of the destination store", "us-east-1")
+	dryRunFlag := flags.NewBoolFlag("dry-run", "", "show what would be migrated without copying anything", false)
+	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "")
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move project data to a new S3 location",
+		Long: "Copy the data of every artifact, dataitem and model of a project to a new S3 location and update their paths.\n" +
+			"Objects are copied server-side when possible and streamed otherwise; sizes are verified before each entity is updated.\n" +
+			"Run the command again to resume an interrupted migration.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if *toFlag.Value == "" {
+				log.Fatalf("Migration failed: --to is required")
+			}
+			err := adapter.StorageMigrateHandler(
+				*envFlag.Value,
+				*outFlag.Value,
+				utils.ResolveProject(*projectFlag.Value),
+				adapter.MigrateOptions{
+					From:       *fromFlag.Value,
+					To:         *toFlag.Value,
+					ToEndpoint: *toEndpointFlag.Value,
+					ToRegion:   *toRegionFlag.Value,
+					DryRun:     *dryRunFlag.Value,
+				},
+			)
+			if err != nil {
+				log.Fatalf("Migration failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &projectFlag)
+	flags.AddFlag(cmd, &toFlag)
+	flags.AddFlag(cmd, &fromFlag)
+	flags.AddFlag(cmd, &toEndpointFlag)
+	flags.AddFlag(cmd, &toRegionFlag)
+	flags.AddFlag(cmd, &dryRunFlag)
+	flags.AddFlag(cmd, &outFlag)
+
+	return cmd
+}()
+
+func init() {
+	storageCmd.AddCommand(storageMigrateCmd)
+	pkg.RegisterCommand(storageCmd)
+}
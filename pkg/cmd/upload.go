@@ -23,6 +23,8 @@ var uploadCmd = func() *cobra.Command {
 	nameFlag := flags.NewStringFlag("name", "n", "Mandatory when creating a new artifact", "")
 	inputFlag := flags.NewStringFlag("file", "f", "Input filename or directory; mandatory", "")
 	verboseFlag := flags.NewBoolFlag("verbose", "v", "Verbose progress/logging", false)
+	archiveFlag := flags.NewStringFlag("archive", "", "Pack a directory into a single archive (zip, tar.gz)", "")
+	inPlaceFlag := flags.NewBoolFlag("in-place", "", "Register the input at its local path without copying it to S3", false)
 
 	cmd := &cobra.Command{
 		Use:   "upload <resource> [<id>]",
@@ -49,6 +51,8 @@ var uploadCmd = func() *cobra.Command {
 				id,
 				*nameFlag.Value,
 				*verboseFlag.Value,
+				*archiveFlag.Value,
+				*inPlaceFlag.Value,
 			)
 			if err != nil {
 				log.Fatalf("Upload failed: %v", err)
@@ -61,6 +65,8 @@ var uploadCmd = func() *cobra.Command {
 	flags.AddFlag(cmd, &nameFlag)
 	flags.AddFlag(cmd, &inputFlag)
 	flags.AddFlag(cmd, &verboseFlag)
+	flags.AddFlag(cmd, &archiveFlag)
+	flags.AddFlag(cmd, &inPlaceFlag)
 
 	return cmd
 }()
@@ -7,18 +7,28 @@ package cmd
 import (
 	"dhcli/handlers/environment"
 	"dhcli/pkg"
+	"dhcli/pkg/flags"
 
 	"github.com/spf13/cobra"
 )
 
-var useCmd = &cobra.Command{
-	Use:   "use <environment>",
-	Short: "Sets the default environment",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		environment.UseHandler(args[0])
-	},
-}
+var useCmd = func() *cobra.Command {
+	asFlag := flags.NewStringFlag("as", "", "identity to use in the environment ('default' for the plain login)", "")
+
+	cmd := &cobra.Command{
+		Use:   "use <environment>",
+		Short: "Sets the default environment",
+		Long:  "Sets the default environment and, with --as, the identity used in it. The identity is kept for the environment until changed again.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			environment.UseHandler(args[0], *asFlag.Value)
+		},
+	}
+
+	flags.AddFlag(cmd, &asFlag)
+
+	return cmd
+}()
 
 func init() {
 	pkg.RegisterCommand(useCmd)
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"log"
+
+	"dhcli/handlers/adapter"
+	"dhcli/handlers/utils"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	projectFlag := flags.NewStringFlag("project", "p", "Mandatory for resources other than projects", "")
+	nameFlag := flags.NewStringFlag("name", "n", "Alternative to id, will verify latest version", "")
+	outFlag := flags.NewStringFlag("out", "o", "Output format (short, json, yaml)", "")
+	dirFlag := flags.NewStringFlag("dir", "d", "Local copy to verify instead of the remote objects", "")
+
+	cmd := &cobra.Command{
+		Use:   "verify <resource> [<id>]",
+		Short: "Verify the files of a resource against its integrity manifest",
+		Long:  "Compare the SHA-256 digests recorded in status.files at upload time with the stored objects, or with a local copy when --dir is given. Missing, extra and mismatched files are reported and the command exits with a non-zero status on drift.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return errors.New("requires 1 or 2 arguments: <resource> [<id>]")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			id := ""
+			if len(args) > 1 {
+				id = args[1]
+			}
+
+			project := utils.ResolveProject(*projectFlag.Value)
+			if err := adapter.VerifyHandler(
+				*envFlag.Value,
+				*outFlag.Value,
+				project,
+				*nameFlag.Value,
+				args[0],
+				id,
+				*dirFlag.Value,
+			); err != nil {
+				log.Fatalf("Verify failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &projectFlag)
+	flags.AddFlag(cmd, &nameFlag)
+	flags.AddFlag(cmd, &outFlag)
+	flags.AddFlag(cmd, &dirFlag)
+
+	return cmd
+}()
+
+func init() {
+	pkg.RegisterCommand(verifyCmd)
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"dhcli/handlers/auth"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var whoamiCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment", "")
+	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "")
+	userinfoFlag := flags.NewBoolFlag("userinfo", "", "query the userinfo endpoint of the provider", false)
+	introspectFlag := flags.NewBoolFlag("introspect", "", "query the token introspection endpoint of the provider", false)
+
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the identity behind the current credentials",
+		Long: `Decode the access and ID tokens of the current environment and show subject,
+username, issuer, audience, scopes, roles, project permissions and expiry.
+Use --userinfo and --introspect to also ask the provider.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := auth.WhoAmIHandler(*outFlag.Value, *userinfoFlag.Value, *introspectFlag.Value); err != nil {
+				log.Fatalf("Whoami failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &outFlag)
+	flags.AddFlag(cmd, &userinfoFlag)
+	flags.AddFlag(cmd, &introspectFlag)
+
+	return cmd
+}()
+
+func init() {
+	pkg.RegisterCommand(whoamiCmd)
+}
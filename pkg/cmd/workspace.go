@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: © 2025 DSLab - Fondazione Bruno Kessler
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"dhcli/handlers/environment"
+	"dhcli/pkg"
+	"dhcli/pkg/flags"
+
+	"github.com/spf13/cobra"
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage the workspace file of a directory",
+	Long: `A .dhcli.yaml file pins the environment, project, default output format and default run
+parameters for the directory it is in and its subdirectories. Flags and environment variables
+(PROJECT_NAME, DHCLI_OUTPUT) still take precedence.`,
+}
+
+var workspaceInitCmd = func() *cobra.Command {
+	envFlag := flags.NewStringFlag("env", "e", "environment to pin (default: current one)", "")
+	projectFlag := flags.NewStringFlag("project", "p", "project to pin", "")
+	defaultOutFlag := flags.NewStringFlag("default-out", "", "default output format (short, json, yaml)", "")
+	forceFlag := flags.NewBoolFlag("force", "", "overwrite an existing workspace file", false)
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a workspace file in the current directory",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := environment.WorkspaceInitHandler(*envFlag.Value, *projectFlag.Value, *defaultOutFlag.Value, *forceFlag.Value); err != nil {
+				log.Fatalf("Workspace init failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &envFlag)
+	flags.AddFlag(cmd, &projectFlag)
+	flags.AddFlag(cmd, &defaultOutFlag)
+	flags.AddFlag(cmd, &forceFlag)
+
+	return cmd
+}()
+
+var workspaceShowCmd = func() *cobra.Command {
+	outFlag := flags.NewStringFlag("out", "o", "output format (short, json, yaml)", "")
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the workspace file in effect",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := environment.WorkspaceShowHandler(*outFlag.Value); err != nil {
+				log.Fatalf("Workspace show failed: %v", err)
+			}
+		},
+	}
+
+	flags.AddFlag(cmd, &outFlag)
+
+	return cmd
+}()
+
+func init() {
+	workspaceCmd.AddCommand(workspaceInitCmd)
+	workspaceCmd.AddCommand(workspaceShowCmd)
+	pkg.RegisterCommand(workspaceCmd)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"slices"
+	"strings"
 
 	"dhcli/handlers/utils"
 
@@ -48,11 +49,31 @@ var dhcli = &cobra.Command{
 			env = envFlag.Value.String()
 		}
 
+		if identityFlag := cmd.Flags().Lookup("identity"); identityFlag != nil {
+			if err := utils.SelectIdentity(identityFlag.Value.String()); err != nil {
+				return err
+			}
+		}
+
 		// Only skip config for explicit maintenance cmds
-		if !(slices.Contains([]string{"register", "use", "remove", "list-env"}, cmd.Name())) {
+		if needsConfig(cmd) {
+			// A .dhcli.yaml in the working tree pins the environment unless --env is given.
+			ws, err := utils.LoadWorkspace()
+			if err != nil {
+				return err
+			}
+			if ws != nil && env == "" {
+				env = ws.Environment
+			}
 			if err := utils.RegisterIniCfgWithViper(env); err != nil {
 				return err
 			}
+			if err := utils.ApplyWorkspace(); err != nil {
+				return err
+			}
+			if err := utils.ConfigureTransportFromConfig(); err != nil {
+				return err
+			}
 		}
 
 		// Show final config
@@ -65,10 +86,22 @@ var dhcli = &cobra.Command{
 	},
 }
 
+// noConfigCommands lists command paths (without the root name) that must not
+// load the INI configuration. Subcommands inherit the setting of their parent.
+var noConfigCommands = []string{"register", "use", "remove", "list-env", "env", "cache", "agent status", "agent stop"}
+
+func needsConfig(cmd *cobra.Command) bool {
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	return !slices.ContainsFunc(noConfigCommands, func(c string) bool {
+		return path == c || strings.HasPrefix(path, c+" ")
+	})
+}
+
 func init() {
 	// Add persistent verbose flag to root command
 	dhcli.PersistentFlags().BoolP("verbose", "v", false, "enable verbose output")
 	dhcli.PersistentFlags().Bool("debug", false, "enable HTTP debug logging")
+	dhcli.PersistentFlags().String("identity", "", "identity of the environment to use, instead of the one set with 'use --as'")
 }
 
 func Execute() {
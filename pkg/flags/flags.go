@@ -9,7 +9,7 @@ import (
 )
 
 type AllowedTypes interface {
-	string | bool | int | float64
+	string | bool | int | float64 | []string
 }
 
 type FlagStruct[T AllowedTypes] struct {
@@ -47,6 +47,28 @@ func NewBoolFlag(name, short, desc string, def bool) FlagStruct[bool] {
 	}
 }
 
+func NewIntFlag(name, short, desc string, def int) FlagStruct[int] {
+	return FlagStruct[int]{
+		Name:         name,
+		Short:        short,
+		Description:  desc,
+		DefaultValue: def,
+		Value:        new(int),
+	}
+}
+
+// NewStringArrayFlag returns a flag that may be repeated, collecting every
+// value given.
+func NewStringArrayFlag(name, short, desc string, def []string) FlagStruct[[]string] {
+	return FlagStruct[[]string]{
+		Name:         name,
+		Short:        short,
+		Description:  desc,
+		DefaultValue: def,
+		Value:        new([]string),
+	}
+}
+
 // === We can implement more helper here ===
 // ...
 
@@ -65,6 +87,8 @@ func AddFlag[T AllowedTypes](cmd *cobra.Command, flag *FlagStruct[T]) {
 		cmd.Flags().IntVarP(v, flag.Name, flag.Short, def.(int), flag.Description)
 	case *float64:
 		cmd.Flags().Float64VarP(v, flag.Name, flag.Short, def.(float64), flag.Description)
+	case *[]string:
+		cmd.Flags().StringArrayVarP(v, flag.Name, flag.Short, def.([]string), flag.Description)
 	default:
 		panic("unsupported flag type")
 	}